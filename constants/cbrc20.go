@@ -3,10 +3,11 @@ package constants
 import "regexp"
 
 const (
-	ProtocolCBRC20  = "c-brc-20"
-	OperationDeploy = "deploy"
-	OperationMint   = "mint"
-	DecimalsDefault = "18"
+	ProtocolCBRC20    = "c-brc-20"
+	OperationDeploy   = "deploy"
+	OperationMint     = "mint"
+	OperationTransfer = "transfer"
+	DecimalsDefault   = "18"
 
 	CInsDescriptionTypeBlockchain = "blockchain"
 	CInsDescriptionTypeOrdinals   = "ordinals"
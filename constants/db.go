@@ -10,16 +10,10 @@ const (
 	DefaultWithFlushNum         = 1_000
 	DefaultFlushCacheNum        = 50_000
 	DefaultFlushOutputTraversed = 50_000
-	DefaultDBListenPort         = "4000"
-	DefaultDbStatusListenPort   = "10080"
 	DefaultDBUser               = "root"
 	DefaultDBPass               = ""
 )
 
-const (
-	TidbSessionMemLimit = 1
-)
-
 func DBDatDir(testnet bool) string {
 	if testnet {
 		return btcutil.AppDataDir(filepath.Join(AppName, "inscription", "index", "testnet"), false)
@@ -0,0 +1,380 @@
+package inscription
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/indexer"
+	"github.com/inscription-c/cins/pkg/util"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BatchModeSeparate reveals every inscription of a batch in a reveal transaction of its own.
+// It is the only batch mode: the indexer curses the inscriptions of a reveal transaction
+// that are not in its first input at offset zero or that have a pointer.
+const BatchModeSeparate = "separate"
+
+// BatchManifest is the manifest of a batch inscribe, read from a YAML or JSON file.
+// Paths in the manifest are relative to the directory of the manifest file.
+type BatchManifest struct {
+	// Mode is the reveal mode of the batch, only separate (default) is supported.
+	Mode string `yaml:"mode" json:"mode"`
+
+	// Postage is the postage of every inscription, the postage flag is used if zero.
+	Postage uint64 `yaml:"postage" json:"postage"`
+
+	// Inscriptions are the inscriptions of the batch, in inscription id order.
+	Inscriptions []BatchEntry `yaml:"inscriptions" json:"inscriptions"`
+}
+
+// BatchEntry is an inscription of a batch manifest. Empty fields fall back to the
+// flags of the inscribe command.
+type BatchEntry struct {
+	File            string `yaml:"file" json:"file"`
+	Destination     string `yaml:"destination" json:"destination"`
+	CInsDescription string `yaml:"c_ins_description" json:"c_ins_description"`
+	JsonMetadata    string `yaml:"json_metadata" json:"json_metadata"`
+	CborMetadata    string `yaml:"cbor_metadata" json:"cbor_metadata"`
+	Delegate        string `yaml:"delegate" json:"delegate"`
+}
+
+// ReadBatchManifest reads a batch manifest from a JSON file if it has a .json extension,
+// from a YAML file otherwise, and resolves the paths of the entries.
+func ReadBatchManifest(path string) (*BatchManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BatchManifest{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, manifest)
+	} else {
+		err = yaml.Unmarshal(data, manifest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Mode == "" {
+		manifest.Mode = BatchModeSeparate
+	}
+	if manifest.Mode != BatchModeSeparate {
+		return nil, fmt.Errorf("invalid batch mode %s, must be %s: the inscriptions after the first of a reveal transaction are indexed as cursed", manifest.Mode, BatchModeSeparate)
+	}
+	if len(manifest.Inscriptions) == 0 {
+		return nil, errors.New("batch manifest has no inscriptions")
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	for idx := range manifest.Inscriptions {
+		entry := &manifest.Inscriptions[idx]
+		if entry.File == "" && entry.Delegate == "" {
+			return nil, fmt.Errorf("batch inscription %d: either file or delegate is required", idx)
+		}
+		if entry.Delegate != "" && tables.StringToInscriptionId(entry.Delegate) == nil {
+			return nil, fmt.Errorf("batch inscription %d: invalid delegate inscription id", idx)
+		}
+		entry.File = resolve(entry.File)
+		entry.CInsDescription = resolve(entry.CInsDescription)
+		entry.JsonMetadata = resolve(entry.JsonMetadata)
+		entry.CborMetadata = resolve(entry.CborMetadata)
+	}
+	return manifest, nil
+}
+
+// Batch is a batch of inscriptions funded by one commit transaction, with one
+// taproot output per inscription. The inscriptions are revealed in one reveal
+// transaction each.
+type Batch struct {
+	// walletClient is the client for the wallet.
+	walletClient *rpcclient.Client
+
+	// indexer is the indexer used to exclude UTXOs holding inscriptions.
+	indexer indexer.IndexerInterface
+
+	// feeEstimator is the fee rate estimator for the transactions.
+	feeEstimator FeeEstimator
+
+	// pinnedOutpoints is the outpoints of the UTXOs the commit transaction must spend.
+	pinnedOutpoints []string
+
+	// minConf is the minimum number of confirmations of the UTXOs spent by the commit transaction.
+	minConf int
+
+	// inscriptions are the inscriptions of the batch.
+	inscriptions []*Inscription
+
+	// feeRate is the fee rate for the transactions.
+	feeRate int64
+
+	// commitFee is the fee and the virtual size of the commit transaction.
+	commitFee TxFee
+
+	// revealFees are the fees and the virtual sizes of the reveal transactions.
+	revealFees []TxFee
+
+	// totalFee is the fee of the commit and reveal transactions.
+	totalFee int64
+
+	// utxo is the unspent transaction outputs spent by the commit transaction.
+	utxo []btcjson.ListUnspentResult
+
+	// pinnedUtxo is the unspent transaction outputs the commit transaction always spends.
+	pinnedUtxo []btcjson.ListUnspentResult
+
+	// candidateUtxo is the unspent transaction outputs the coin selection selects from.
+	candidateUtxo []btcjson.ListUnspentResult
+
+	// priKey is the temporary private key shared by the reveal scripts.
+	priKey *btcec.PrivateKey
+
+	// commitTx is the commit transaction of the batch.
+	commitTx *wire.MsgTx
+
+	// revealTxs are the reveal transactions of the batch.
+	revealTxs []*wire.MsgTx
+}
+
+// NewBatch is a function that creates a new Batch from a manifest. The options are
+// applied to every inscription of the batch before the options of its manifest entry.
+func NewBatch(manifest *BatchManifest, inputOpts ...Option) (*Batch, error) {
+	opts := &options{}
+	for _, option := range inputOpts {
+		option(opts)
+	}
+
+	batch := &Batch{
+		walletClient:    opts.walletClient,
+		indexer:         opts.indexer,
+		feeEstimator:    opts.feeRateEstimator(),
+		pinnedOutpoints: opts.utxo,
+		minConf:         opts.minConf,
+	}
+	for idx, entry := range manifest.Inscriptions {
+		entryOpts := append([]Option{}, inputOpts...)
+		if manifest.Postage > 0 {
+			entryOpts = append(entryOpts, WithPostage(manifest.Postage))
+		}
+		if entry.Destination != "" {
+			entryOpts = append(entryOpts, WithDestination(entry.Destination))
+		}
+		if entry.CInsDescription != "" {
+			cInsDescription, err := tables.CInsDescriptionFromFile(entry.CInsDescription)
+			if err != nil {
+				return nil, fmt.Errorf("batch inscription %d: %w", idx, err)
+			}
+			entryOpts = append(entryOpts, WithCInsDescription(cInsDescription))
+		}
+		if entry.JsonMetadata != "" {
+			entryOpts = append(entryOpts, WithJsonMetadata(entry.JsonMetadata))
+		}
+		if entry.CborMetadata != "" {
+			entryOpts = append(entryOpts, WithCborMetadata(entry.CborMetadata))
+		}
+		if entry.Delegate != "" {
+			entryOpts = append(entryOpts, WithDelegate(tables.StringToInscriptionId(entry.Delegate)))
+		}
+
+		var inscription *Inscription
+		var err error
+		if entry.File != "" {
+			inscription, err = NewFromPath(entry.File, entryOpts...)
+		} else {
+			inscription, err = NewFromData(nil, nil, entryOpts...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch inscription %d: %w", idx, err)
+		}
+		if inscription.options.destination == "" {
+			return nil, fmt.Errorf("batch inscription %d: destination is required", idx)
+		}
+		if inscription.Header.CInsDescription == nil {
+			return nil, fmt.Errorf("batch inscription %d: c_ins_description is required", idx)
+		}
+		batch.inscriptions = append(batch.inscriptions, inscription)
+	}
+	return batch, nil
+}
+
+// Wallet is a method of the Batch struct. It returns the wallet client of the Batch.
+func (b *Batch) Wallet() *rpcclient.Client {
+	return b.walletClient
+}
+
+// getUtxo is a method of the Batch struct. It lists the pinned UTXOs and the candidate
+// UTXOs of the coin selection, excluding locked UTXOs and the UTXOs holding inscriptions.
+func (b *Batch) getUtxo() error {
+	pinned, candidates, err := listUtxo(b.Wallet(), b.indexer, b.minConf, b.pinnedOutpoints)
+	if err != nil {
+		return err
+	}
+	b.pinnedUtxo = pinned
+	b.candidateUtxo = candidates
+	return nil
+}
+
+// CreateInscriptionTx is a method of the Batch struct. It estimates the fee rate,
+// generates a temporary private key, builds the reveal script and reveal transaction
+// of every inscription, and builds the commit transaction funding all of them.
+// It returns an error if there is an error in any of the steps.
+func (b *Batch) CreateInscriptionTx() error {
+	feeRate, err := b.feeEstimator.EstimateFeeRate()
+	if err != nil {
+		return err
+	}
+	b.feeRate = feeRate
+
+	// gen temporary priKey
+	priKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return err
+	}
+	b.priKey = priKey
+
+	// build the reveal script and a single input reveal tx of every inscription
+	for _, inscription := range b.inscriptions {
+		inscription.feeRate = feeRate
+		inscription.priKey = priKey
+		if err := inscription.BuildRevealTx(); err != nil {
+			return err
+		}
+	}
+
+	// build the reveal txs and the commit outputs paying them
+	commitOutputs := make([]*wire.TxOut, 0, len(b.inscriptions))
+	var revealFees int64
+	for _, inscription := range b.inscriptions {
+		revealFees += inscription.revealFee
+		b.revealFees = append(b.revealFees, TxFee{VSize: inscription.revealVSize, Fee: inscription.revealFee})
+		commitOutputs = append(commitOutputs, wire.NewTxOut(int64(inscription.options.postage)+inscription.revealFee, nil))
+		b.revealTxs = append(b.revealTxs, inscription.revealTx)
+	}
+	for idx, inscription := range b.inscriptions {
+		recipientScript, err := util.AddressScript(inscription.revealTxAddress.String(), util.ActiveNet.Params)
+		if err != nil {
+			return err
+		}
+		commitOutputs[idx].PkScript = recipientScript
+	}
+
+	// build commit tx
+	commitTx, utxo, commitFee, err := buildCommitTx(b.Wallet(), b.pinnedUtxo, b.candidateUtxo, commitOutputs, feeRate)
+	if err != nil {
+		return err
+	}
+	b.commitTx = commitTx
+	b.utxo = utxo
+	b.commitFee = commitFee
+	b.totalFee = commitFee.Fee + revealFees
+	for idx, inscription := range b.inscriptions {
+		inscription.commitTx = commitTx
+		inscription.vout = idx
+	}
+	b.linkRevealTxs()
+	return nil
+}
+
+// linkRevealTxs is a method of the Batch struct. It points the input of every
+// inscription in the reveal transactions to its output of the commit transaction.
+func (b *Batch) linkRevealTxs() {
+	commitHash := b.commitTx.TxHash()
+	for idx, inscription := range b.inscriptions {
+		inscription.revealTx.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(&commitHash, uint32(idx))
+	}
+}
+
+// SignCommitTx is a method of the Batch struct. It signs the commit transaction
+// with the private keys of the wallet.
+func (b *Batch) SignCommitTx() error {
+	return signCommitTx(b.Wallet(), b.commitTx, b.utxo)
+}
+
+// SignRevealTx is a method of the Batch struct. It signs the reveal transactions
+// with the temporary private key, after the commit transaction has been signed.
+func (b *Batch) SignRevealTx() error {
+	for _, inscription := range b.inscriptions {
+		if err := inscription.SignRevealTx(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupPrivKey is a method of the Batch struct. It imports the temporary private key into the wallet.
+func (b *Batch) backupPrivKey() error {
+	return backupPrivKey(b.Wallet(), b.priKey)
+}
+
+// session is a method of the Batch struct. It returns the session of the signed
+// commit and reveal transactions of the Batch.
+func (b *Batch) session() (*Session, error) {
+	reveals := make([]*SessionReveal, 0, len(b.inscriptions))
+	for idx, inscription := range b.inscriptions {
+		controlBlock, err := inscription.controlBlock.ToBytes()
+		if err != nil {
+			return nil, err
+		}
+		reveals = append(reveals, newSessionReveal(idx, inscription.revealScript, controlBlock))
+	}
+	inscriptions := make([]string, 0, len(b.inscriptions))
+	for _, v := range b.Output().Inscriptions {
+		inscriptions = append(inscriptions, v.Id)
+	}
+	return newSession(b.priKey, b.commitTx, b.revealTxs, reveals, inscriptions)
+}
+
+// Output is a method of the Batch struct. It returns the transaction ids, the
+// inscription ids and their locations, and the total fees of the batch.
+func (b *Batch) Output() *BatchOutput {
+	out := &BatchOutput{
+		Commit:     b.commitTx.TxHash().String(),
+		FeeRate:    float64(b.feeRate) / 1000,
+		CommitFee:  b.commitFee,
+		RevealFees: b.revealFees,
+		TotalFees:  b.totalFee,
+	}
+	for _, revealTx := range b.revealTxs {
+		out.Reveals = append(out.Reveals, revealTx.TxHash().String())
+	}
+	for _, inscription := range b.inscriptions {
+		revealTxId := inscription.RevealTxId()
+		out.Inscriptions = append(out.Inscriptions, BatchInscriptionOutput{
+			Id:          tables.NewInscriptionId(revealTxId, 0).String(),
+			Destination: inscription.options.destination,
+			Location:    fmt.Sprintf("%s:0:0", revealTxId),
+		})
+	}
+	return out
+}
+
+// BatchOutput is the output of a batch inscribe.
+type BatchOutput struct {
+	Commit       string                   `json:"commit"`
+	Reveals      []string                 `json:"reveals"`
+	Inscriptions []BatchInscriptionOutput `json:"inscriptions"`
+	FeeRate      float64                  `json:"fee_rate"`
+	CommitFee    TxFee                    `json:"commit_fee"`
+	RevealFees   []TxFee                  `json:"reveal_fees"`
+	TotalFees    int64                    `json:"total_fees"`
+}
+
+// BatchInscriptionOutput is an inscription of the output of a batch inscribe.
+type BatchInscriptionOutput struct {
+	Id          string `json:"id"`
+	Destination string `json:"destination"`
+	Location    string `json:"location"`
+}
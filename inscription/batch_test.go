@@ -0,0 +1,74 @@
+package inscription
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/util"
+	"gotest.tools/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBatchManifest(t *testing.T) {
+	manifest, err := ReadBatchManifest("./test/batch.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, manifest.Mode, BatchModeSeparate)
+	assert.Equal(t, manifest.Postage, uint64(546))
+	assert.Equal(t, len(manifest.Inscriptions), 2)
+	assert.Equal(t, manifest.Inscriptions[0].File, filepath.Join("test", "cbrc20.json"))
+	assert.Equal(t, manifest.Inscriptions[1].CInsDescription, filepath.Join("test", "c_ins_description.json"))
+	assert.Equal(t, manifest.Inscriptions[1].Destination, "tb1qmzsf0567v7aj9wnnqm3ml2neqhu3leavt7jz8p")
+}
+
+func TestReadBatchManifestShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.yaml")
+	manifest := "mode: shared\ninscriptions:\n  - file: cbrc20.json\n"
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ReadBatchManifest(path)
+	assert.ErrorContains(t, err, "indexed as cursed")
+}
+
+// TestSharedRevealCursed checks why a batch cannot share a reveal transaction: the
+// indexer curses every inscription revealed from an input after the first.
+func TestSharedRevealCursed(t *testing.T) {
+	priKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	revealTx := wire.NewMsgTx(2)
+	for idx := 0; idx < 2; idx++ {
+		body := &util.DefaultProtocol{}
+		body.Reset([]byte("cins"))
+		revealScript, err := InscriptionToScript(priKey.PubKey(), Header{
+			CInsDescription: &tables.CInsDescription{},
+			ContentType:     constants.ContentTypeJson,
+		}, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		controlBlock, _, err := RevealScriptAddress(priKey.PubKey(), revealScript)
+		if err != nil {
+			t.Fatal(err)
+		}
+		controlBlockBytes, err := controlBlock.ToBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		revealTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(idx)}, nil,
+			wire.TxWitness{make([]byte, 64), revealScript, controlBlockBytes}))
+		revealTx.AddTxOut(wire.NewTxOut(546, nil))
+	}
+
+	envelopes := index.ParsedEnvelopFromTransaction(revealTx)
+	assert.Equal(t, len(envelopes), 2)
+	assert.Equal(t, envelopes[0].Curse(), index.Curse(0))
+	assert.Equal(t, envelopes[1].Curse(), index.CurseNotInFirstInput)
+}
@@ -0,0 +1,244 @@
+package inscription
+
+import (
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wallet/txsizes"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/pkg/indexer"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+const (
+	// maxConf is the maximum number of confirmations of the listed UTXOs.
+	maxConf = 9999999
+
+	// bnbMaxTries is the maximum number of branches the branch-and-bound selection explores.
+	bnbMaxTries = 100000
+
+	// changeOutputWeight is the weight of the nested P2WPKH change output of the commit transaction.
+	changeOutputWeight = (8 + 1 + txsizes.NestedP2WPKHPkScriptSize) * 4
+
+	// txInWeight is the weight of a transaction input without signature script and witness.
+	txInWeight = (32 + 4 + 1 + 4) * 4
+)
+
+// listUtxo is a function that lists the UTXOs of the wallet with at least minConf
+// confirmations. The wallet doesn't report nested P2WPKH and taproot UTXOs as spendable,
+// so UTXOs are spendable if their script class is one the commit transaction can spend,
+// see utxoRedeemWeight, which includes the watch-only UTXOs of these classes. UTXOs of other
+// script classes are excluded, the size of their signature script and witness is unknown.
+// It returns the pinned UTXOs, which are always spent, and the candidate UTXOs for the coin
+// selection. Locked UTXOs and UTXOs holding inscriptions are excluded, pinning one of them is an error.
+func listUtxo(walletCli *rpcclient.Client, idx indexer.IndexerInterface, minConf int, pinnedOutpoints []string) (
+	pinned, candidates []btcjson.ListUnspentResult, err error) {
+	unspentUtxo, err := walletCli.ListUnspentMinMax(minConf, maxConf)
+	if err != nil {
+		return nil, nil, err
+	}
+	lockedUtxo, err := walletCli.ListLockUnspent()
+	if err != nil {
+		return nil, nil, err
+	}
+	pinnedSet := make(map[string]bool, len(pinnedOutpoints))
+	for _, v := range pinnedOutpoints {
+		outpoint, err := wire.NewOutPointFromString(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid utxo %s: %w", v, err)
+		}
+		pinnedSet[outpoint.String()] = true
+	}
+	// the wallet doesn't list locked UTXOs as unspent
+	locked := make(map[string]bool, len(lockedUtxo))
+	for _, v := range lockedUtxo {
+		if pinnedSet[v.String()] {
+			return nil, nil, fmt.Errorf("utxo %s is locked", v.String())
+		}
+		locked[v.String()] = true
+	}
+
+	utxo := make([]btcjson.ListUnspentResult, 0, len(unspentUtxo))
+	outpoints := make([]string, 0, len(unspentUtxo))
+	for _, v := range unspentUtxo {
+		outpoint := utxoOutpoint(v)
+		if locked[outpoint] {
+			continue
+		}
+		if redeemWeight, _ := utxoRedeemWeight(v); redeemWeight == 0 {
+			if pinnedSet[outpoint] {
+				return nil, nil, fmt.Errorf("utxo %s has an unsupported script, its fee cannot be estimated", outpoint)
+			}
+			continue
+		}
+		utxo = append(utxo, v)
+		outpoints = append(outpoints, outpoint)
+	}
+
+	// check the inscriptions of all UTXOs with one indexer request
+	inscriptions := make(map[string][]string, len(outpoints))
+	for start := 0; start < len(outpoints); start += 1000 {
+		end := start + 1000
+		if end > len(outpoints) {
+			end = len(outpoints)
+		}
+		resp, err := idx.Outpoints(outpoints[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, v := range resp {
+			inscriptions[k] = v
+		}
+	}
+
+	for _, v := range utxo {
+		outpoint := utxoOutpoint(v)
+		if pinnedSet[outpoint] {
+			if len(inscriptions[outpoint]) > 0 {
+				return nil, nil, fmt.Errorf("utxo %s holds inscriptions %v", outpoint, inscriptions[outpoint])
+			}
+			pinned = append(pinned, v)
+			delete(pinnedSet, outpoint)
+			continue
+		}
+		if len(inscriptions[outpoint]) == 0 {
+			candidates = append(candidates, v)
+		}
+	}
+	for outpoint := range pinnedSet {
+		return nil, nil, fmt.Errorf("utxo %s is not a spendable unspent output of the wallet with %d confirmations", outpoint, minConf)
+	}
+	return pinned, candidates, nil
+}
+
+// utxoOutpoint is a function that returns the outpoint string of a UTXO.
+func utxoOutpoint(utxo btcjson.ListUnspentResult) string {
+	return fmt.Sprintf("%s%s%d", utxo.TxID, constants.OutpointDelimiter, utxo.Vout)
+}
+
+// utxoValue is a function that returns the value of a UTXO in satoshis.
+func utxoValue(utxo btcjson.ListUnspentResult) int64 {
+	return decimal.NewFromFloat(utxo.Amount).Mul(decimal.NewFromInt(int64(constants.OneBtc))).IntPart()
+}
+
+// inputFee is a function that returns the fee of spending a UTXO at a fee rate in
+// satoshis per kilo virtual byte, rounded up.
+func inputFee(utxo btcjson.ListUnspentResult, feeRate int64) int64 {
+	redeemWeight, _ := utxoRedeemWeight(utxo)
+	return decimal.NewFromInt(txInWeight + redeemWeight).
+		Mul(decimal.NewFromInt(feeRate)).
+		Div(decimal.NewFromInt(4000)).Ceil().IntPart()
+}
+
+// selectCoins is a function that selects the UTXOs funding a transaction with the
+// outputs of tx at a fee rate in satoshis per kilo virtual byte. The pinned UTXOs
+// are always selected. The candidates are selected with branch-and-bound, looking
+// for a selection which doesn't need a change output, and with largest-first if
+// there is none. It returns InsufficientBalanceError if the UTXOs cannot pay the
+// outputs and the fee.
+func selectCoins(tx *wire.MsgTx, pinned, candidates []btcjson.ListUnspentResult, feeRate int64) ([]btcjson.ListUnspentResult, error) {
+	var target int64
+	for _, out := range tx.TxOut {
+		target += out.Value
+	}
+
+	// the fee of the outputs and the pinned inputs, with the segwit marker and flag
+	selected := append([]btcjson.ListUnspentResult{}, pinned...)
+	need := target + calculateWeightFee(txWeight(tx)+2, feeRate)
+	for _, v := range pinned {
+		need -= utxoValue(v) - inputFee(v, feeRate)
+	}
+	if need <= 0 {
+		return selected, nil
+	}
+
+	// candidates worth spending, by descending effective value
+	type coin struct {
+		utxo  btcjson.ListUnspentResult
+		value int64
+	}
+	coins := make([]coin, 0, len(candidates))
+	var available int64
+	for _, v := range candidates {
+		value := utxoValue(v) - inputFee(v, feeRate)
+		if value <= 0 {
+			continue
+		}
+		coins = append(coins, coin{utxo: v, value: value})
+		available += value
+	}
+	if available < need {
+		return nil, InsufficientBalanceError
+	}
+	sort.SliceStable(coins, func(i, j int) bool {
+		return coins[i].value > coins[j].value
+	})
+	values := make([]int64, len(coins))
+	for j, c := range coins {
+		values[j] = c.value
+	}
+
+	// A change output is only added if the change is above the dust limit.
+	costOfChange := calculateWeightFee(changeOutputWeight, feeRate) + constants.DustLimit
+	if picked := branchAndBound(values, need, costOfChange); picked != nil {
+		for _, j := range picked {
+			selected = append(selected, coins[j].utxo)
+		}
+		return selected, nil
+	}
+
+	// largest-first, up to the target with a change output
+	var sum int64
+	for _, c := range coins {
+		selected = append(selected, c.utxo)
+		sum += c.value
+		if sum >= need+costOfChange {
+			break
+		}
+	}
+	return selected, nil
+}
+
+// branchAndBound is a function that searches the subset of the values, sorted in
+// descending order, with a sum between target and target+costOfChange and the least
+// excess. It returns the indexes of the subset, or nil if there is none.
+func branchAndBound(values []int64, target, costOfChange int64) []int {
+	remaining := make([]int64, len(values)+1)
+	for j := len(values) - 1; j >= 0; j-- {
+		remaining[j] = remaining[j+1] + values[j]
+	}
+
+	var best []int
+	bestExcess := int64(-1)
+	current := make([]int, 0, len(values))
+	tries := 0
+	var search func(depth int, sum int64)
+	search = func(depth int, sum int64) {
+		tries++
+		if tries > bnbMaxTries || bestExcess == 0 {
+			return
+		}
+		if sum > target+costOfChange || sum+remaining[depth] < target {
+			return
+		}
+		if sum >= target {
+			if excess := sum - target; bestExcess < 0 || excess < bestExcess {
+				bestExcess = excess
+				best = append([]int{}, current...)
+			}
+			return
+		}
+		if depth == len(values) {
+			return
+		}
+		// include the value first, then omit it
+		current = append(current, depth)
+		search(depth+1, sum+values[depth])
+		current = current[:len(current)-1]
+		search(depth+1, sum)
+	}
+	search(0, 0)
+	return best
+}
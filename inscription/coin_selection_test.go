@@ -0,0 +1,49 @@
+package inscription
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestBranchAndBound(t *testing.T) {
+	values := []int64{50000, 30000, 20000, 10000}
+	assert.DeepEqual(t, branchAndBound(values, 40000, 0), []int{1, 3})
+	assert.DeepEqual(t, branchAndBound(values, 60000, 0), []int{0, 3})
+	assert.DeepEqual(t, branchAndBound(values, 35000, 1000), []int(nil))
+	assert.DeepEqual(t, branchAndBound(values, 200000, 1000), []int(nil))
+}
+
+func TestSelectCoins(t *testing.T) {
+	newUtxo := func(txid string, amount float64) btcjson.ListUnspentResult {
+		return btcjson.ListUnspentResult{
+			TxID:         txid,
+			Amount:       amount,
+			ScriptPubKey: "0014" + "0000000000000000000000000000000000000000",
+		}
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxOut(wire.NewTxOut(20000, make([]byte, 34)))
+
+	candidates := []btcjson.ListUnspentResult{
+		newUtxo("a", 0.001),
+		newUtxo("b", 0.0005),
+		newUtxo("c", 0.0001),
+	}
+
+	// largest first without an exact match
+	selected, err := selectCoins(tx, nil, candidates, 1000)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(selected), 1)
+	assert.Equal(t, selected[0].TxID, "a")
+
+	// pinned UTXOs are always spent
+	selected, err = selectCoins(tx, []btcjson.ListUnspentResult{newUtxo("c", 0.0001)}, candidates[:2], 1000)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(selected), 2)
+	assert.Equal(t, selected[0].TxID, "c")
+
+	_, err = selectCoins(tx, nil, candidates[2:], 1000)
+	assert.Equal(t, err, InsufficientBalanceError)
+}
@@ -0,0 +1,144 @@
+package inscription
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	txscript2 "github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wallet/txsizes"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultTargetBlocks is the default confirmation target of the node fee estimation.
+const DefaultTargetBlocks = 10
+
+// FeeEstimator estimates the fee rate of the commit and reveal transactions.
+type FeeEstimator interface {
+	// EstimateFeeRate returns the fee rate in satoshis per kilo virtual byte.
+	EstimateFeeRate() (int64, error)
+}
+
+// StaticFeeEstimator is a FeeEstimator returning a fixed fee rate.
+type StaticFeeEstimator struct {
+	feeRate int64
+}
+
+// NewStaticFeeEstimator creates a StaticFeeEstimator from a fee rate in satoshis per virtual byte.
+func NewStaticFeeEstimator(satPerVByte float64) *StaticFeeEstimator {
+	return &StaticFeeEstimator{
+		feeRate: decimal.NewFromFloat(satPerVByte).Mul(decimal.NewFromInt(1000)).IntPart(),
+	}
+}
+
+// EstimateFeeRate returns the fixed fee rate.
+func (s *StaticFeeEstimator) EstimateFeeRate() (int64, error) {
+	return s.feeRate, nil
+}
+
+// NodeFeeEstimator is a FeeEstimator asking the wallet backend node for a fee rate,
+// with estimatefee on btcd and estimatesmartfee on bitcoind.
+type NodeFeeEstimator struct {
+	walletClient *rpcclient.Client
+	targetBlocks int64
+}
+
+// NewNodeFeeEstimator creates a NodeFeeEstimator for a confirmation target in blocks.
+func NewNodeFeeEstimator(walletClient *rpcclient.Client, targetBlocks int64) *NodeFeeEstimator {
+	return &NodeFeeEstimator{
+		walletClient: walletClient,
+		targetBlocks: targetBlocks,
+	}
+}
+
+// EstimateFeeRate returns the fee rate estimated by the node for the confirmation target.
+func (n *NodeFeeEstimator) EstimateFeeRate() (int64, error) {
+	backendVersion, err := n.walletClient.BackendVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	var feeRate float64
+	if backendVersion == rpcclient.Btcd {
+		feeRate, err = n.walletClient.EstimateFee(n.targetBlocks)
+		if err != nil {
+			return 0, fmt.Errorf("estimate fee: %w, set fee_rate to skip the estimation", err)
+		}
+	} else {
+		var resp *btcjson.EstimateSmartFeeResult
+		resp, err = n.walletClient.EstimateSmartFee(n.targetBlocks, &btcjson.EstimateModeConservative)
+		if err != nil {
+			return 0, fmt.Errorf("estimate fee: %w, set fee_rate to skip the estimation", err)
+		}
+		if len(resp.Errors) > 0 || resp.FeeRate == nil {
+			return 0, fmt.Errorf("estimate fee: %s, set fee_rate to skip the estimation", gconv.String(resp.Errors))
+		}
+		feeRate = *resp.FeeRate
+	}
+	if feeRate <= 0 {
+		return 0, errors.New("estimate fee: no fee rate, set fee_rate to skip the estimation")
+	}
+	return int64(index.AmountToSat(feeRate)), nil
+}
+
+// TxFee is the virtual size and the fee of a transaction.
+type TxFee struct {
+	VSize int64 `json:"vsize"`
+	Fee   int64 `json:"fee"`
+}
+
+// txWeight is a function that calculates the weight of a transaction as it is serialized.
+func txWeight(tx *wire.MsgTx) int64 {
+	return int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+}
+
+// signedTxWeight is a function that estimates the weight of an unsigned transaction
+// spending the given UTXOs once its inputs are signed by the wallet.
+func signedTxWeight(tx *wire.MsgTx, utxo []btcjson.ListUnspentResult) int64 {
+	weight := txWeight(tx)
+	witness, legacyInputs := false, int64(0)
+	for _, v := range utxo {
+		redeemWeight, isWitness := utxoRedeemWeight(v)
+		weight += redeemWeight
+		if isWitness {
+			witness = true
+		} else {
+			legacyInputs++
+		}
+	}
+	// segwit marker and flag, and the empty witness of the legacy inputs
+	if witness && !tx.HasWitness() {
+		weight += 2 + legacyInputs
+	}
+	return weight
+}
+
+// utxoRedeemWeight is a function that estimates the weight of the signature script
+// and the witness spending a UTXO of the wallet, and whether it is a witness input.
+func utxoRedeemWeight(utxo btcjson.ListUnspentResult) (int64, bool) {
+	pkScript, err := hex.DecodeString(utxo.ScriptPubKey)
+	if err != nil {
+		return 0, false
+	}
+	switch txscript2.GetScriptClass(pkScript) {
+	case txscript2.PubKeyHashTy:
+		return txsizes.RedeemP2PKHSigScriptSize * 4, false
+	case txscript2.WitnessV0PubKeyHashTy:
+		return txsizes.RedeemP2WPKHInputWitnessWeight, true
+	case txscript2.ScriptHashTy:
+		// the wallet only creates nested P2WPKH script hash addresses
+		return txsizes.RedeemNestedP2WPKHScriptSize*4 + txsizes.RedeemP2WPKHInputWitnessWeight, true
+	case txscript2.WitnessV1TaprootTy:
+		return txsizes.RedeemP2TRInputWitnessWeight, true
+	}
+	return 0, false
+}
+
+// weightToVSize is a function that converts a transaction weight to virtual bytes.
+func weightToVSize(weight int64) int64 {
+	return (weight + 3) / 4
+}
@@ -0,0 +1,28 @@
+package inscription
+
+import (
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wallet/txsizes"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestStaticFeeEstimator(t *testing.T) {
+	feeRate, err := NewStaticFeeEstimator(2.5).EstimateFeeRate()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, feeRate, int64(2500))
+	assert.Equal(t, calculateWeightFee(4000, feeRate), int64(2500))
+}
+
+func TestSignedTxWeight(t *testing.T) {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, make([]byte, txsizes.P2WPKHPkScriptSize)))
+	utxo := []btcjson.ListUnspentResult{{
+		ScriptPubKey: "0014" + "0000000000000000000000000000000000000000",
+	}}
+	weight := signedTxWeight(tx, utxo)
+	assert.Equal(t, weight, txWeight(tx)+txsizes.RedeemP2WPKHInputWitnessWeight+2)
+	assert.Equal(t, weightToVSize(weight), int64(txsizes.EstimateVirtualSize(0, 0, 1, 0, []*wire.TxOut{tx.TxOut[0]}, 0)))
+}
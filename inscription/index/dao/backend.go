@@ -0,0 +1,117 @@
+package dao
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/glebarez/sqlite"
+	gormMysqlDriver "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage backends supported by the index database.
+const (
+	BackendMysql  = "mysql"  // MySQL or a MySQL compatible server
+	BackendSqlite = "sqlite" // embedded SQLite database file, for development and testnet
+)
+
+// Backend is a storage backend of the index database.
+// The dao methods only use portable SQL, so a backend only deals with connecting and migrating.
+type Backend interface {
+	// Dialector returns the gorm dialector connecting to the database.
+	Dialector(opts *DBOptions) (gorm.Dialector, error)
+	// Migrate creates or updates the tables.
+	Migrate(db *gorm.DB, tables ...interface{}) error
+	// SetupPool configures the connection pool of the database.
+	SetupPool(db *sql.DB)
+	// BatchSize is the number of rows inserted at once by a batch insert.
+	BatchSize() int
+}
+
+// backends are the supported storage backends by name.
+var backends = map[string]Backend{
+	BackendMysql:  &mysqlBackend{},
+	BackendSqlite: &sqliteBackend{},
+}
+
+// mysqlBackend stores the index in a MySQL server.
+type mysqlBackend struct{}
+
+func (b *mysqlBackend) Dialector(opts *DBOptions) (gorm.Dialector, error) {
+	conn := "%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local"
+	dsn := fmt.Sprintf(conn, opts.user, opts.password, opts.addr, opts.dbName)
+	return gormMysqlDriver.Open(dsn), nil
+}
+
+// Migrate creates or updates the tables.
+// Indexes used to be named without the table name, which SQLite does not allow,
+// so indexes left with their old name are dropped.
+func (b *mysqlBackend) Migrate(db *gorm.DB, tables ...interface{}) error {
+	if err := db.AutoMigrate(tables...); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(table); err != nil {
+			return err
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			for _, prefix := range []string{"idx_", "uk_"} {
+				if !strings.HasPrefix(index.Name, prefix+stmt.Schema.Table+"_") {
+					continue
+				}
+				legacy := prefix + strings.TrimPrefix(index.Name, prefix+stmt.Schema.Table+"_")
+				if db.Migrator().HasIndex(table, legacy) {
+					if err := db.Migrator().DropIndex(table, legacy); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (b *mysqlBackend) SetupPool(db *sql.DB) {
+	db.SetMaxOpenConns(100)
+	db.SetMaxIdleConns(50)
+}
+
+func (b *mysqlBackend) BatchSize() int {
+	return 10_000
+}
+
+// sqliteBackend stores the index in an embedded SQLite database file.
+// The database runs in WAL mode, so the API can read while the indexer writes.
+type sqliteBackend struct{}
+
+func (b *sqliteBackend) Dialector(opts *DBOptions) (gorm.Dialector, error) {
+	if opts.path == "" {
+		return nil, errors.New("sqlite database path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.path), 0700); err != nil {
+		return nil, err
+	}
+	dsn := opts.path + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+	return sqlite.Open(dsn), nil
+}
+
+// Migrate creates or updates the tables.
+// The column types of the tables are MySQL types SQLite accepts as type names,
+// and the primary keys have no column type, so SQLite makes them auto increment row ids.
+func (b *sqliteBackend) Migrate(db *gorm.DB, tables ...interface{}) error {
+	return db.AutoMigrate(tables...)
+}
+
+func (b *sqliteBackend) SetupPool(db *sql.DB) {
+	db.SetMaxOpenConns(8)
+	db.SetMaxIdleConns(8)
+}
+
+// BatchSize keeps batch inserts below the SQLite limit of 32766 bound variables.
+func (b *sqliteBackend) BatchSize() int {
+	return 1_000
+}
@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gorm.io/gorm"
+)
+
+// GetBalance retrieves the balance of an address for a specific TkId.
+// It returns a zero balance if the address never held the token.
+func (d *DB) GetBalance(tkid, address string) (balance tables.Balance, err error) {
+	err = d.Where("tkid=? and address=?", tkid, address).First(&balance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// UpdateBalance adds the given deltas to the available and transferable balance of an address.
+// It creates the balance if it does not exist, and returns an error if a balance would become negative.
+// It returns any error encountered during the operation.
+func (d *DB) UpdateBalance(height uint32, tkid, ticker, address string, available, transferable int64) error {
+	balance := &tables.Balance{}
+	err := d.Where("tkid=? and address=?", tkid, address).First(balance).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if int64(balance.Available)+available < 0 || int64(balance.Transferable)+transferable < 0 {
+		return fmt.Errorf("balance of %s for %s would become negative", address, tkid)
+	}
+
+	if balance.Id == 0 {
+		balance.TkId = tkid
+		balance.Ticker = ticker
+		balance.Address = address
+		balance.Available = uint64(available)
+		balance.Transferable = uint64(transferable)
+		balance.Total = balance.Available + balance.Transferable
+		if err := d.Create(balance).Error; err != nil {
+			return err
+		}
+		return d.undoInsert(height, balance)
+	}
+
+	if err := d.undoUpdate(height, balance); err != nil {
+		return err
+	}
+	balance.Available = uint64(int64(balance.Available) + available)
+	balance.Transferable = uint64(int64(balance.Transferable) + transferable)
+	balance.Total = balance.Available + balance.Transferable
+	return d.Save(balance).Error
+}
+
+// FindBalancesByAddress finds the balances of an address for all tokens.
+// It returns a list of balances and any error encountered.
+func (d *DB) FindBalancesByAddress(address string) (list []*tables.Balance, err error) {
+	err = d.Where("address=? and total>0", address).Order("id").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindHoldersByTkId finds the holders for a specific TkId, ordered by their total balance.
+// It returns a list of holders and any error encountered.
+func (d *DB) FindHoldersByTkId(tkid string, page, pageSize int) (list []*tables.Balance, err error) {
+	err = d.Where("tkid=? and total>0", tkid).Order("total desc, id").
+		Offset((page - 1) * pageSize).Limit(pageSize + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// CountHoldersByTkId counts the addresses holding a specific TkId.
+// It returns the total count and any error encountered.
+func (d *DB) CountHoldersByTkId(tkid string) (total int64, err error) {
+	err = d.Model(&tables.Balance{}).Where("tkid=? and total>0", tkid).Count(&total).Error
+	return
+}
@@ -1,12 +1,10 @@
 package dao
 
 import (
-	"encoding/hex"
 	"errors"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 // BlockHeader retrieves the block header for a given block height.
@@ -92,31 +90,27 @@ func (d *DB) SaveBlockInfo(block *tables.BlockInfo) error {
 		if err := d.Save(block).Error; err != nil {
 			return err
 		}
-		sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Save(old)
-		})
-		return d.AddUndoLog(block.Height, sql)
+		return d.undoUpdate(block.Height, old)
 	}
 	if err := d.Create(block).Error; err != nil {
 		return err
 	}
-	sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		return tx.Delete(block)
-	})
-	return d.AddUndoLog(block.Height, sql)
+	return d.undoInsert(block.Height, block)
 }
 
 // DeleteBlockInfoByHeight deletes a block info from the database by height.
 func (d *DB) DeleteBlockInfoByHeight(height uint32) (info tables.BlockInfo, err error) {
-	if err = d.Clauses(clause.Returning{}).Where("height = ?", height).Delete(&info).Error; err != nil {
+	err = d.Where("height = ?", height).First(&info).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+		return
+	}
+	if err != nil {
 		return
 	}
-	if info.Id > 0 {
-		sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Create(&info)
-		})
-		sql = SqlFix(sql, hex.EncodeToString(info.Header))
-		err = d.AddUndoLog(height, sql)
+	if err = d.Delete(&info).Error; err != nil {
+		return
 	}
+	err = d.undoDelete(height, &info)
 	return
 }
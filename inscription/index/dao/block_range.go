@@ -0,0 +1,329 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+)
+
+// DeleteBlocksAfter rolls the index back to the block at the given height without undo logs,
+// deleting the rows of the blocks indexed after it and restoring the rows they changed:
+// the locations and owners of the inscriptions moved since, the sends of c-brc-20 transfers,
+// the minted totals of the deploys, the balances and the lost sats. The inscription counters
+// are left to the caller, see CountInscriptions. The savepoints and undo logs after the height
+// are deleted, the ones at or below it are kept, the restored rows are not recorded in them.
+//
+// It returns an error if the index can not be rebuilt at the height: the block is not indexed,
+// sats are indexed, the lost sats after the block are unknown, or the location history of a
+// moved inscription was not recorded. Outpoint values are a cache of the node and are kept.
+func (d *DB) DeleteBlocksAfter(height uint32) error {
+	block := &tables.BlockInfo{}
+	if err := d.Where("height = ?", height).First(block).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("block %d is not indexed", height)
+		}
+		return err
+	}
+	indexSats, err := d.GetStatisticCountByName(tables.StatisticIndexSats)
+	if err != nil {
+		return err
+	}
+	if indexSats > 0 {
+		return errors.New("the sat ranges of an index with sats can only be rolled back with the undo logs")
+	}
+	lostSats := uint64(0)
+	if block.LostSats != nil {
+		lostSats = *block.LostSats
+	} else {
+		current, err := d.GetStatisticCountByName(tables.StatisticLostSats)
+		if err != nil {
+			return err
+		}
+		if current > 0 {
+			return fmt.Errorf("the lost sats after block %d are unknown, it was indexed by an older version", height)
+		}
+	}
+
+	balances := make(balanceDeltas)
+	if err := d.restoreMovedInscriptions(height, balances); err != nil {
+		return err
+	}
+	if err := d.deleteNewInscriptions(height, balances); err != nil {
+		return err
+	}
+	if err := d.applyBalanceDeltas(balances); err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{&tables.InscriptionTransfer{}, &tables.BlockInfo{}} {
+		if err := d.Where("height > ?", height).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := d.SetStatistic(height, tables.StatisticLostSats, lostSats); err != nil {
+		return err
+	}
+	if err := d.Where("height > ?", height).Delete(&tables.SavePoint{}).Error; err != nil {
+		return err
+	}
+	return d.Where("height > ?", height).Delete(&tables.UndoLog{}).Error
+}
+
+// balanceDelta is a change of the available and transferable balance of an address.
+type balanceDelta struct {
+	available    int64
+	transferable int64
+}
+
+// balanceDeltas are the balance changes to make, by tkid and address.
+type balanceDeltas map[[2]string]*balanceDelta
+
+// add adds a change of the balance of an address.
+func (b balanceDeltas) add(tkid, address string, available, transferable int64) {
+	key := [2]string{tkid, address}
+	delta, ok := b[key]
+	if !ok {
+		delta = &balanceDelta{}
+		b[key] = delta
+	}
+	delta.available += available
+	delta.transferable += transferable
+}
+
+// undoSend reverts the send of a c-brc-20 transfer to its receiver.
+func (b balanceDeltas) undoSend(transfer *tables.Protocol) {
+	amount := int64(transfer.Amount)
+	b.add(transfer.TkId, transfer.To, -amount, 0)
+	b.add(transfer.TkId, transfer.Owner, 0, amount)
+}
+
+// restoreMovedInscriptions restores the locations and owners at the given height of the inscriptions
+// moved after it, and of the inscriptions whose satpoint was taken over after it.
+// The c-brc-20 transfers first sent after the height are unsent.
+func (d *DB) restoreMovedInscriptions(height uint32, balances balanceDeltas) error {
+	sequenceNums := make([]int64, 0)
+	if err := d.Model(&tables.InscriptionTransfer{}).Distinct("sequence_num").
+		Where("height <= ? AND (sequence_num IN (?) OR new_sat_point IN (?))",
+			height,
+			d.Model(&tables.InscriptionTransfer{}).Select("sequence_num").Where("height > ?", height),
+			d.Model(&tables.InscriptionTransfer{}).Select("new_sat_point").Where("height > ?", height),
+		).Order("sequence_num").Pluck("sequence_num", &sequenceNums).Error; err != nil {
+		return err
+	}
+	// Inscriptions created before the transfer history was recorded have no location at the height.
+	var unknown int64
+	if err := d.Model(&tables.InscriptionTransfer{}).Distinct("sequence_num").
+		Where("height > ? AND sequence_num IN (?) AND sequence_num NOT IN (?)",
+			height,
+			d.Model(&tables.Inscriptions{}).Select("sequence_num").Where("height <= ?", height),
+			d.Model(&tables.InscriptionTransfer{}).Select("sequence_num").Where("height <= ?", height),
+		).Count(&unknown).Error; err != nil {
+		return err
+	}
+	if unknown > 0 {
+		return fmt.Errorf("the location history at height %d of %d inscriptions moved since was not recorded", height, unknown)
+	}
+
+	for start := 0; start < len(sequenceNums); start += undoLogBatchSize {
+		batch := sequenceNums[start:min(start+undoLogBatchSize, len(sequenceNums))]
+		if err := d.restoreLocations(height, batch); err != nil {
+			return err
+		}
+		if err := d.unsendTransfers(height, batch, balances); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreLocations restores the satpoints and current owners of inscriptions at the given height
+// from their last location change at or below it. An inscription whose satpoint was taken over by a
+// later inscription at or below the height has no satpoint, like after SetSatPointToSequenceNum.
+func (d *DB) restoreLocations(height uint32, sequenceNums []int64) error {
+	transfers := make([]*tables.InscriptionTransfer, 0, len(sequenceNums))
+	if err := d.Where("id IN (?)", d.Model(&tables.InscriptionTransfer{}).Select("max(id)").
+		Where("height <= ? AND sequence_num IN ?", height, sequenceNums).Group("sequence_num"),
+	).Find(&transfers).Error; err != nil {
+		return err
+	}
+	satPoints := make([]string, 0, len(transfers))
+	for _, transfer := range transfers {
+		satPoints = append(satPoints, transfer.NewSatPoint)
+	}
+	var latest []struct {
+		NewSatPoint string
+		Id          uint64
+	}
+	if err := d.Model(&tables.InscriptionTransfer{}).Select("new_sat_point, max(id) AS id").
+		Where("height <= ? AND new_sat_point IN ?", height, satPoints).
+		Group("new_sat_point").Scan(&latest).Error; err != nil {
+		return err
+	}
+	latestIds := make(map[string]uint64, len(latest))
+	for _, v := range latest {
+		latestIds[v.NewSatPoint] = v.Id
+	}
+
+	if err := d.Where("sequence_num IN ?", sequenceNums).Delete(&tables.SatPointToSequenceNum{}).Error; err != nil {
+		return err
+	}
+	for _, transfer := range transfers {
+		if latestIds[transfer.NewSatPoint] == transfer.Id {
+			satPoint, err := parseSatPoint(transfer.NewSatPoint)
+			if err != nil {
+				return err
+			}
+			satPoint.SequenceNum = transfer.SequenceNum
+			if err := d.Create(satPoint).Error; err != nil {
+				return err
+			}
+		}
+		if err := d.Model(&tables.Inscriptions{}).Where("sequence_num = ?", transfer.SequenceNum).
+			Update("current_owner", transfer.Owner).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSatPoint parses the satpoint of a location change, the null outpoint is the location of lost inscriptions.
+func parseSatPoint(s string) (*tables.SatPointToSequenceNum, error) {
+	i := strings.LastIndex(s, constants.OutpointDelimiter)
+	if i < 0 {
+		return nil, fmt.Errorf("invalid satpoint %s", s)
+	}
+	offset, err := strconv.ParseUint(s[i+1:], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid satpoint %s: %w", s, err)
+	}
+	satPoint := &tables.SatPointToSequenceNum{
+		Outpoint: s[:i],
+		Offset:   offset,
+	}
+	if satPoint.Outpoint == (wire.OutPoint{}).String() {
+		satPoint.Outpoint = ""
+	}
+	return satPoint, nil
+}
+
+// unsendTransfers unsends the c-brc-20 transfers of the given inscriptions that were not moved at or below the height.
+func (d *DB) unsendTransfers(height uint32, sequenceNums []int64, balances balanceDeltas) error {
+	list := make([]*tables.Protocol, 0)
+	if err := d.Where("protocol = ? AND operator = ? AND `to` <> '' AND sequence_num IN ? AND sequence_num NOT IN (?)",
+		constants.ProtocolCBRC20, constants.OperationTransfer, sequenceNums,
+		d.Model(&tables.InscriptionTransfer{}).Select("sequence_num").
+			Where("height <= ? AND old_sat_point <> '' AND sequence_num IN ?", height, sequenceNums),
+	).Find(&list).Error; err != nil {
+		return err
+	}
+	for _, transfer := range list {
+		balances.undoSend(transfer)
+		if err := d.Model(transfer).Update("to", "").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteNewInscriptions deletes the inscriptions created after the given height with their protocol operations,
+// parents, sats and satpoints. The balance changes and the minted amounts of their c-brc-20 operations are reverted.
+func (d *DB) deleteNewInscriptions(height uint32, balances balanceDeltas) error {
+	created := d.Model(&tables.Inscriptions{}).Select("sequence_num").Where("height > ?", height)
+
+	list := make([]*tables.Protocol, 0)
+	if err := d.Where("protocol = ? AND sequence_num IN (?)", constants.ProtocolCBRC20, created).Find(&list).Error; err != nil {
+		return err
+	}
+	minted := make(map[string]uint64)
+	for _, op := range list {
+		amount := int64(op.Amount)
+		switch op.Operator {
+		case constants.OperationMint:
+			minted[op.TkId] += op.Amount
+			balances.add(op.TkId, op.Owner, -amount, 0)
+		case constants.OperationTransfer:
+			if op.To != "" {
+				balances.undoSend(op)
+			}
+			balances.add(op.TkId, op.Owner, amount, -amount)
+		}
+	}
+	for tkid, amount := range minted {
+		deploy := tables.StringToInscriptionId(tkid)
+		if err := d.Model(&tables.Protocol{}).Where("tx_id = ? AND offset = ?", deploy.TxId, deploy.Offset).
+			Update("minted", gorm.Expr("minted - ?", amount)).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{
+		&tables.Protocol{},
+		&tables.InscriptionParent{},
+		&tables.SatToSequenceNum{},
+		&tables.SatPointToSequenceNum{},
+	} {
+		if err := d.Where("sequence_num IN (?)", created).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return d.Where("height > ?", height).Delete(&tables.Inscriptions{}).Error
+}
+
+// applyBalanceDeltas applies the balance changes, it returns an error if a balance would become negative.
+func (d *DB) applyBalanceDeltas(balances balanceDeltas) error {
+	for key, delta := range balances {
+		if delta.available == 0 && delta.transferable == 0 {
+			continue
+		}
+		balance := &tables.Balance{}
+		if err := d.Where("tkid = ? AND address = ?", key[0], key[1]).First(balance).Error; err != nil {
+			return fmt.Errorf("balance of %s for %s: %w", key[1], key[0], err)
+		}
+		available := int64(balance.Available) + delta.available
+		transferable := int64(balance.Transferable) + delta.transferable
+		if available < 0 || transferable < 0 {
+			return fmt.Errorf("balance of %s for %s would become negative", key[1], key[0])
+		}
+		balance.Available = uint64(available)
+		balance.Transferable = uint64(transferable)
+		balance.Total = balance.Available + balance.Transferable
+		if err := d.Save(balance).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BlockRows returns the rows of the blocks from height from to height to, grouped by table: the blocks,
+// the inscriptions created and the location changes in them, the protocol operations, parents and
+// sats of the inscriptions created in them, and the non-zero balances of the c-brc-20 tokens of the
+// operations created or sent in them. The rows are ordered by id.
+func (d *DB) BlockRows(from, to uint32) (map[string][]map[string]interface{}, error) {
+	created := d.Model(&tables.Inscriptions{}).Select("sequence_num").Where("height BETWEEN ? AND ?", from, to)
+	moved := d.Model(&tables.InscriptionTransfer{}).Select("sequence_num").Where("height BETWEEN ? AND ?", from, to)
+	touched := d.Model(&tables.Protocol{}).Select("tkid").
+		Where("protocol = ? AND (sequence_num IN (?) OR sequence_num IN (?))", constants.ProtocolCBRC20, created, moved)
+	queries := map[interface{}]*gorm.DB{
+		&tables.BlockInfo{}:           d.Where("height BETWEEN ? AND ?", from, to),
+		&tables.Inscriptions{}:        d.Where("height BETWEEN ? AND ?", from, to),
+		&tables.InscriptionTransfer{}: d.Where("height BETWEEN ? AND ?", from, to),
+		&tables.InscriptionParent{}:   d.Where("sequence_num IN (?)", created),
+		&tables.Protocol{}:            d.Where("sequence_num IN (?)", created),
+		&tables.SatToSequenceNum{}:    d.Where("sequence_num IN (?)", created),
+		&tables.Balance{}:             d.Where("tkid IN (?) AND total > 0", touched),
+	}
+	res := make(map[string][]map[string]interface{}, len(queries))
+	for model, query := range queries {
+		rows := make([]map[string]interface{}, 0)
+		if err := query.Model(model).Order("id").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		res[model.(interface{ TableName() string }).TableName()] = rows
+	}
+	return res, nil
+}
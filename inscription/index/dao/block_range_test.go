@@ -0,0 +1,248 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gotest.tools/assert"
+	"sort"
+	"testing"
+)
+
+// indexInscriptionBlock makes the changes indexing a block of inscriptions makes: it creates the inscription of the
+// block, which is a c-brc-20 deploy at height 1, a mint at height 2 and a transfer at height 4, and moves the
+// inscriptions of the previous blocks to an output of the block, which sends the transfer.
+func indexInscriptionBlock(db *DB, height uint32) error {
+	return db.Transaction(func(tx *DB) error {
+		lostSats := uint64(height)
+		if err := tx.SaveBlockInfo(&tables.BlockInfo{
+			Height:    height,
+			Header:    []byte{byte(height), 0, 1},
+			Timestamp: int64(height),
+			LostSats:  &lostSats,
+		}); err != nil {
+			return err
+		}
+		for name, count := range map[tables.StatisticType]uint64{
+			tables.StatisticLostSats:            lostSats,
+			tables.StatisticCursedInscriptions:  0,
+			tables.StatisticUnboundInscriptions: 0,
+		} {
+			if err := tx.SetStatistic(height, name, count); err != nil {
+				return err
+			}
+		}
+		if height == 0 {
+			return nil
+		}
+
+		txId := fmt.Sprintf("%064d", height)
+		outpoint := tables.FormatOutpoint(txId, 0)
+		owner := fmt.Sprintf("owner %d", height)
+		moved, err := tx.InscriptionsByOutpoint(tables.FormatOutpoint(fmt.Sprintf("%064d", height-1), 0))
+		if err != nil {
+			return err
+		}
+		for i, ins := range moved {
+			if err := tx.DeleteBySatPoint(height, ins.SatPointToSequenceNum); err != nil {
+				return err
+			}
+			satPoint := &tables.SatPointToSequenceNum{Outpoint: outpoint, Offset: uint64(i + 1), SequenceNum: ins.Inscriptions.SequenceNum}
+			if err := tx.SetSatPointToSequenceNum(height, satPoint); err != nil {
+				return err
+			}
+			if err := tx.UpdateInscriptionOwner(height, ins.Inscriptions.SequenceNum, owner); err != nil {
+				return err
+			}
+			transfer, err := tx.GetProtocolByInscriptionId(&ins.InscriptionId)
+			if err != nil {
+				return err
+			}
+			if transfer.Operator == constants.OperationTransfer && transfer.To == "" {
+				transfer.To = owner
+				if err := tx.SaveProtocol(height, &transfer); err != nil {
+					return err
+				}
+				if err := tx.UpdateBalance(height, transfer.TkId, transfer.Ticker, transfer.Owner, 0, -int64(transfer.Amount)); err != nil {
+					return err
+				}
+				if err := tx.UpdateBalance(height, transfer.TkId, transfer.Ticker, owner, int64(transfer.Amount), 0); err != nil {
+					return err
+				}
+			}
+			if err := tx.CreateInscriptionTransfer(height, &tables.InscriptionTransfer{
+				SequenceNum: ins.Inscriptions.SequenceNum,
+				TxId:        txId,
+				Height:      height,
+				OldSatPoint: tables.FormatSatPoint(ins.Outpoint, ins.Offset),
+				NewSatPoint: satPoint.String(),
+				Owner:       owner,
+			}); err != nil {
+				return err
+			}
+		}
+
+		sequenceNum := int64(height)
+		creator := fmt.Sprintf("creator %d", height%2)
+		ins := &tables.Inscriptions{
+			InscriptionId:  tables.InscriptionId{TxId: txId},
+			SequenceNum:    sequenceNum,
+			InscriptionNum: sequenceNum - 1,
+			Owner:          creator,
+			CurrentOwner:   creator,
+			Height:         height,
+		}
+		if err := tx.CreateInscription(ins); err != nil {
+			return err
+		}
+		satPoint := &tables.SatPointToSequenceNum{Outpoint: outpoint, SequenceNum: sequenceNum}
+		if err := tx.SetSatPointToSequenceNum(height, satPoint); err != nil {
+			return err
+		}
+		if err := tx.CreateInscriptionTransfer(height, &tables.InscriptionTransfer{
+			SequenceNum: sequenceNum,
+			TxId:        txId,
+			Height:      height,
+			NewSatPoint: satPoint.String(),
+			Owner:       creator,
+		}); err != nil {
+			return err
+		}
+		if height > 1 {
+			if err := tx.CreateInscriptionParent(height, sequenceNum, sequenceNum-1); err != nil {
+				return err
+			}
+		}
+		if err := tx.SetStatistic(height, tables.StatisticBlessedInscriptions, uint64(sequenceNum)); err != nil {
+			return err
+		}
+
+		tkid := tables.NewInscriptionId(fmt.Sprintf("%064d", 1), 0).String()
+		protocol := &tables.Protocol{
+			InscriptionId: ins.InscriptionId,
+			SequenceNum:   sequenceNum,
+			Protocol:      constants.ProtocolCBRC20,
+			Ticker:        "tick",
+			Owner:         creator,
+		}
+		switch height {
+		case 1:
+			protocol.Operator = constants.OperationDeploy
+			return tx.SaveProtocol(height, protocol)
+		case 2:
+			deploy, err := tx.GetProtocolByInscriptionId(tables.StringToInscriptionId(tkid))
+			if err != nil {
+				return err
+			}
+			deploy.Minted += 100
+			if err := tx.SaveProtocol(height, &deploy); err != nil {
+				return err
+			}
+			protocol.Operator, protocol.TkId, protocol.Amount = constants.OperationMint, tkid, 100
+			if err := tx.SaveProtocol(height, protocol); err != nil {
+				return err
+			}
+			return tx.UpdateBalance(height, tkid, "tick", creator, 100, 0)
+		case 4:
+			protocol.Operator, protocol.TkId, protocol.Amount = constants.OperationTransfer, tkid, 40
+			if err := tx.SaveProtocol(height, protocol); err != nil {
+				return err
+			}
+			return tx.UpdateBalance(height, tkid, "tick", creator, -40, 40)
+		}
+		return nil
+	})
+}
+
+// rowSet returns the rows of the index tables without their ids and timestamps, in a stable order.
+// Balances that were emptied are left out.
+func rowSet(t *testing.T, db *DB) map[string][]string {
+	res := make(map[string][]string)
+	for name, rows := range snapshot(t, db) {
+		list := make([]string, 0, len(rows))
+		for _, row := range rows {
+			if name == (&tables.Balance{}).TableName() && fmt.Sprint(row["total"]) == "0" {
+				continue
+			}
+			for _, column := range []string{"id", "created_at", "updated_at"} {
+				delete(row, column)
+			}
+			data, err := json.Marshal(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			list = append(list, string(data))
+		}
+		sort.Strings(list)
+		res[name] = list
+	}
+	return res
+}
+
+func TestDeleteBlocksAfter(t *testing.T) {
+	db := testDB(t)
+
+	snapshots := make(map[uint32]map[string][]string)
+	for height := uint32(0); height <= 6; height++ {
+		assert.NilError(t, indexInscriptionBlock(db, height))
+		snapshots[height] = rowSet(t, db)
+	}
+
+	// Roll back the send of the transfer at height 5, then its inscribing and the mint.
+	for _, height := range []uint32{4, 3, 1} {
+		var kept, lastId int64
+		assert.NilError(t, db.Model(&tables.UndoLog{}).Where("height <= ?", height).Count(&kept).Error)
+		assert.NilError(t, db.Model(&tables.UndoLog{}).Select("COALESCE(max(id), 0)").Scan(&lastId).Error)
+		assert.NilError(t, db.Transaction(func(tx *DB) error {
+			if err := tx.DeleteBlocksAfter(height); err != nil {
+				return err
+			}
+			// the indexer restores the counters from the inscriptions left
+			counts, err := tx.CountInscriptions(0)
+			if err != nil {
+				return err
+			}
+			return tx.SetStatistic(height, tables.StatisticBlessedInscriptions, counts.Blessed)
+		}))
+		assert.DeepEqual(t, rowSet(t, db), snapshots[height])
+		count, err := db.BlockCount()
+		assert.NilError(t, err)
+		assert.Equal(t, count, height+1)
+
+		// The undo logs at or below the height are kept.
+		var after, later int64
+		assert.NilError(t, db.Model(&tables.UndoLog{}).Where("height <= ? AND id <= ?", height, lastId).Count(&after).Error)
+		assert.NilError(t, db.Model(&tables.UndoLog{}).Where("height > ?", height).Count(&later).Error)
+		assert.Equal(t, after, kept)
+		assert.Equal(t, later, int64(0))
+	}
+
+	// The index is rebuilt on top of the height.
+	for height := uint32(2); height <= 6; height++ {
+		assert.NilError(t, indexInscriptionBlock(db, height))
+	}
+	assert.DeepEqual(t, rowSet(t, db), snapshots[6])
+
+	// A block that is not indexed and an index with sats can not be rolled back to.
+	assert.ErrorContains(t, db.DeleteBlocksAfter(7), "block 7 is not indexed")
+	assert.NilError(t, db.SetStatistic(6, tables.StatisticIndexSats, 1))
+	assert.ErrorContains(t, db.DeleteBlocksAfter(3), "sats")
+}
+
+func TestBlockRowsBalances(t *testing.T) {
+	db := testDB(t)
+	for height := uint32(0); height <= 2; height++ {
+		assert.NilError(t, indexInscriptionBlock(db, height))
+	}
+
+	// The deploy touches no balance, the mint touches the balance of the minter.
+	rows, err := db.BlockRows(1, 1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(rows[(&tables.Balance{}).TableName()]), 0)
+	rows, err = db.BlockRows(2, 2)
+	assert.NilError(t, err)
+	balances := rows[(&tables.Balance{}).TableName()]
+	assert.Equal(t, len(balances), 1)
+	assert.Equal(t, balances[0]["address"], "creator 0")
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"github.com/btcsuite/btclog"
 	"github.com/go-sql-driver/mysql"
-	gormMysqlDriver "gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"time"
@@ -22,21 +21,32 @@ type DB struct {
 
 // DBOptions is a struct that holds the configuration options for the database.
 type DBOptions struct {
+	backend           string
 	addr              string
 	user              string
 	password          string
 	dbName            string
-	embedDB           bool
-	dataDir           string
-	serverPort        string
-	serverStatusPort  string
-	startHeight       uint32
+	path              string
 	autoMigrateTables []interface{}
 }
 
 // DBOption is a function type that modifies DBOptions.
 type DBOption func(*DBOptions)
 
+// WithBackend returns a DBOption that sets the storage backend of the database, BackendMysql by default.
+func WithBackend(backend string) DBOption {
+	return func(o *DBOptions) {
+		o.backend = backend
+	}
+}
+
+// WithPath returns a DBOption that sets the database file of the SQLite backend.
+func WithPath(path string) DBOption {
+	return func(o *DBOptions) {
+		o.path = path
+	}
+}
+
 // WithAddr returns a DBOption that sets the address of the database.
 func WithAddr(addr string) DBOption {
 	return func(o *DBOptions) {
@@ -72,71 +82,63 @@ func WithAutoMigrateTables(tables ...interface{}) DBOption {
 	}
 }
 
-// WithEmbedDB returns a DBOption that sets whether to use an embedded database or not.
-func WithEmbedDB(embed bool) DBOption {
-	return func(options *DBOptions) {
-		options.embedDB = embed
-	}
-}
-
-// WithDataDir returns a DBOption that sets the data directory of the database.
-func WithDataDir(dir string) DBOption {
-	return func(o *DBOptions) {
-		o.dataDir = dir
-	}
-}
-
-// WithServerPort returns a DBOption that sets the server port of the database.
-func WithServerPort(port string) DBOption {
-	return func(o *DBOptions) {
-		o.serverPort = port
-	}
-}
-
-// WithStatusPort returns a DBOption that sets the status port of the database.
-func WithStatusPort(port string) DBOption {
-	return func(o *DBOptions) {
-		o.serverStatusPort = port
-	}
-}
-
 // Transaction is a method on DB that executes a function within a database transaction.
 func (d *DB) Transaction(fn func(tx *DB) error) error {
 	return d.DB.Transaction(func(tx *gorm.DB) error {
-		d := &DB{DB: tx}
+		d := &DB{opts: d.opts, DB: tx}
 		return fn(d)
 	})
 }
 
+// BeginTransaction is a method on DB that starts a database transaction.
+// The transaction is ended with Commit or Rollback.
+func (d *DB) BeginTransaction() *DB {
+	return &DB{opts: d.opts, DB: d.DB.Begin()}
+}
+
 // NewDB is a function that creates a new DB instance with the provided options.
 func NewDB(opts ...DBOption) (*DB, error) {
-	options := &DBOptions{}
+	options := &DBOptions{
+		backend: BackendMysql,
+	}
 	for _, opt := range opts {
 		opt(options)
 	}
+	backend, ok := backends[options.backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown db backend %s", options.backend)
+	}
 
 	gormLog := &GormLogger{Logger: inscLog.Gorm}
 	if err := mysql.SetLogger(gormLog); err != nil {
 		return nil, err
 	}
 
-	conn := "%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local"
-	dsn := fmt.Sprintf(conn, options.user, options.password, options.addr, options.dbName)
-	db, err := gorm.Open(gormMysqlDriver.Open(dsn), &gorm.Config{Logger: gormLog})
+	dialector, err := backend.Dialector(options)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: gormLog})
 	if err != nil {
 		return nil, fmt.Errorf("gorm open :%v", err)
 	}
-	if err := db.AutoMigrate(options.autoMigrateTables...); err != nil {
+	var upgrades []upgrade
+	if len(options.autoMigrateTables) > 0 {
+		upgrades = pendingUpgrades(db)
+	}
+	if err := backend.Migrate(db, options.autoMigrateTables...); err != nil {
 		return nil, err
 	}
+	if err := runUpgrades(db, upgrades); err != nil {
+		return nil, fmt.Errorf("upgrade index: %v", err)
+	}
 
 	db = db.Debug()
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("gorm db :%v", err)
 	}
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetMaxIdleConns(50)
+	backend.SetupPool(sqlDB)
 
 	return &DB{
 		opts: options,
@@ -144,8 +146,12 @@ func NewDB(opts ...DBOption) (*DB, error) {
 	}, nil
 }
 
-func (d *DB) EmbedDB() bool {
-	return d.opts.embedDB
+// batchSize returns the number of rows inserted at once by a batch insert.
+func (d *DB) batchSize() int {
+	if d.opts == nil {
+		return backends[BackendMysql].BatchSize()
+	}
+	return backends[d.opts.backend].BatchSize()
 }
 
 // GormLogger is a struct that embeds btclog.Logger to provide additional logging functionality.
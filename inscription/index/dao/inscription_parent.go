@@ -0,0 +1,47 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gorm.io/gorm"
+)
+
+// CreateInscriptionParent creates the relation between a child inscription and its parent.
+// It returns any error encountered.
+func (d *DB) CreateInscriptionParent(height uint32, sequenceNum, parentSequenceNum int64) error {
+	relation := &tables.InscriptionParent{
+		SequenceNum:       sequenceNum,
+		ParentSequenceNum: parentSequenceNum,
+	}
+	if err := d.Create(relation).Error; err != nil {
+		return err
+	}
+	return d.undoInsert(height, relation)
+}
+
+// FindParentsBySequenceNum finds the parent inscription ids of an inscription.
+// It returns a list of inscription IDs and any error encountered.
+func (d *DB) FindParentsBySequenceNum(sequenceNum int64) (list []*tables.InscriptionId, err error) {
+	err = d.Model(&tables.Inscriptions{}).Select("inscriptions.tx_id, inscriptions.offset").
+		Joins("JOIN inscription_parent ON inscriptions.sequence_num=inscription_parent.parent_sequence_num").
+		Where("inscription_parent.sequence_num=?", sequenceNum).
+		Order("inscription_parent.id").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// FindChildrenBySequenceNum retrieves a page of the child inscription ids of an inscription.
+// It returns a list of inscription IDs and any error encountered.
+func (d *DB) FindChildrenBySequenceNum(sequenceNum int64, page, size int) (list []*tables.InscriptionId, err error) {
+	err = d.Model(&tables.Inscriptions{}).Select("inscriptions.tx_id, inscriptions.offset").
+		Joins("JOIN inscription_parent ON inscriptions.sequence_num=inscription_parent.sequence_num").
+		Where("inscription_parent.parent_sequence_num=?", sequenceNum).
+		Order("inscription_parent.sequence_num").
+		Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
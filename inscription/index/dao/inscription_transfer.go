@@ -0,0 +1,28 @@
+package dao
+
+import (
+	"errors"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gorm.io/gorm"
+)
+
+// CreateInscriptionTransfer records a location change of an inscription.
+// It returns any error encountered.
+func (d *DB) CreateInscriptionTransfer(height uint32, transfer *tables.InscriptionTransfer) error {
+	if err := d.Create(transfer).Error; err != nil {
+		return err
+	}
+	return d.undoInsert(height, transfer)
+}
+
+// FindTransfersBySequenceNum retrieves a page of the location changes of an inscription, oldest first.
+// It returns a list of transfers and any error encountered.
+func (d *DB) FindTransfersBySequenceNum(sequenceNum int64, page, size int) (list []*tables.InscriptionTransfer, err error) {
+	err = d.Where("sequence_num=?", sequenceNum).
+		Order("id").
+		Offset((page - 1) * size).Limit(size + 1).Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
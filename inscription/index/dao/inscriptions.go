@@ -1,14 +1,11 @@
 package dao
 
 import (
-	"encoding/hex"
 	"errors"
 	"github.com/inscription-c/cins/constants"
 	"github.com/inscription-c/cins/inscription/index/model"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
-	"strings"
 )
 
 // Inscription is a struct that embeds tables.Inscriptions and util.SatPoint.
@@ -95,22 +92,19 @@ func (d *DB) InscriptionsByOutpoint(outpoint string) (res []*Inscription, err er
 // It returns the sequence number of the deleted inscription and any error encountered.
 func (d *DB) DeleteInscriptionById(height uint32, inscriptionId *tables.InscriptionId) (sequenceNum int64, err error) {
 	ins := &tables.Inscriptions{}
-	err = d.Clauses(clause.Returning{}).Where("tx_id=? and offset=?", inscriptionId.TxId, inscriptionId.Offset).Delete(ins).Error
+	err = d.Where("tx_id=? and offset=?", inscriptionId.TxId, inscriptionId.Offset).First(ins).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+		return
+	}
 	if err != nil {
 		return
 	}
 	sequenceNum = ins.SequenceNum
-
-	if ins.Id > 0 {
-		sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Create(ins)
-		})
-		sql = SqlFix(sql, hex.EncodeToString(ins.Body), hex.EncodeToString(ins.Metadata))
-		err = d.Create(&tables.UndoLog{
-			Height: height,
-			Sql:    sql,
-		}).Error
+	if err = d.Delete(ins).Error; err != nil {
+		return
 	}
+	err = d.undoDelete(height, ins)
 	return
 }
 
@@ -120,12 +114,48 @@ func (d *DB) CreateInscription(ins *tables.Inscriptions) error {
 	if err := d.Create(ins).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: ins.Height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Delete(ins)
-		}),
-	}).Error
+	return d.undoInsert(ins.Height, ins)
+}
+
+// UpdateInscriptionOwner sets the current owner of an inscription after it moved.
+// It returns any error encountered.
+func (d *DB) UpdateInscriptionOwner(height uint32, sequenceNum int64, owner string) error {
+	ins := &tables.Inscriptions{}
+	if err := d.Select("id, current_owner, updated_at").Where("sequence_num=?", sequenceNum).First(ins).Error; err != nil {
+		return err
+	}
+	if ins.CurrentOwner == owner {
+		return nil
+	}
+	if err := d.undoUpdate(height, ins, "current_owner", "updated_at"); err != nil {
+		return err
+	}
+	return d.Model(ins).Update("current_owner", owner).Error
+}
+
+// AddressInscription is an inscription held by an address together with its current satpoint.
+type AddressInscription struct {
+	tables.InscriptionId
+	InscriptionNum int64
+	ContentType    string
+	Outpoint       string
+	SatOffset      uint64
+}
+
+// FindInscriptionsByCurrentOwner retrieves a page of the inscriptions currently owned by an address.
+// It returns a list of inscriptions with their satpoints and any error encountered.
+func (d *DB) FindInscriptionsByCurrentOwner(owner string, page, size int) (list []*AddressInscription, err error) {
+	err = d.Model(&tables.Inscriptions{}).
+		Select("inscriptions.tx_id, inscriptions.offset, inscriptions.inscription_num, inscriptions.content_type, "+
+			"sat_point_to_sequence_num.outpoint, sat_point_to_sequence_num.offset as sat_offset").
+		Joins("JOIN sat_point_to_sequence_num ON inscriptions.sequence_num=sat_point_to_sequence_num.sequence_num").
+		Where("inscriptions.current_owner=?", owner).
+		Order("inscriptions.sequence_num").
+		Offset((page - 1) * size).Limit(size + 1).Scan(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
 }
 
 func (d *DB) DeleteMockInscriptions() error {
@@ -252,41 +282,44 @@ func (d *DB) FirstInscriptionByOwner(owner string) (firts tables.Inscriptions, e
 	return
 }
 
+// FindProtocolsParams holds the filters of SearchInscriptions.
+// Charms is a bit mask of charm flags, every set charm must be present on a matched inscription.
 type FindProtocolsParams struct {
 	Page            int
 	Limit           int
-	Owner           string
+	Owner           string // current owner
+	GenesisOwner    string
 	Ticker          string
 	Order           string
 	MediaTypes      []string
 	ContentTypes    []string
-	Charms          []string
+	Charms          uint16
 	InscriptionType string
+	Metaprotocol    string
 }
 
+// SearchInscriptions returns a page of inscriptions matching the params and the total number of matches.
 func (d *DB) SearchInscriptions(params *FindProtocolsParams) (list []*tables.Inscriptions, total int64, err error) {
 	db := d.Model(&tables.Inscriptions{})
-	charms := make(map[string]struct{})
-	for _, v := range params.Charms {
-		if _, ok := charms[v]; ok {
-			continue
-		}
-		charms[v] = struct{}{}
-		switch v {
-		case "cursed":
-			db = db.Where("inscriptions.inscription_num < 0")
-		}
+	if params.Charms > 0 {
+		db = db.Where("inscriptions.charms & ? = ?", params.Charms, params.Charms)
 	}
 	if params.Ticker != "" {
 		db = db.Joins("JOIN protocol ON inscriptions.sequence_num=protocol.sequence_num").
 			Where("protocol.protocol=? and protocol.ticker=?", constants.ProtocolCBRC20, params.Ticker)
 	}
 	if params.Owner != "" {
-		db = db.Where("inscriptions.owner=?", params.Owner)
+		db = db.Where("inscriptions.current_owner=?", params.Owner)
+	}
+	if params.GenesisOwner != "" {
+		db = db.Where("inscriptions.owner=?", params.GenesisOwner)
 	}
 	if params.InscriptionType != "" {
 		db = db.Where("inscriptions.content_protocol=?", params.InscriptionType)
 	}
+	if params.Metaprotocol != "" {
+		db = db.Where("inscriptions.metaprotocol=?", params.Metaprotocol)
+	}
 	if len(params.MediaTypes) > 0 {
 		db = db.Where("inscriptions.media_type in (?)", params.MediaTypes)
 	}
@@ -296,7 +329,7 @@ func (d *DB) SearchInscriptions(params *FindProtocolsParams) (list []*tables.Ins
 	switch params.Order {
 	case "newest":
 		db = db.Order("inscriptions.id desc")
-	case "oldest":
+	default:
 		db = db.Order("inscriptions.id asc")
 	}
 
@@ -306,17 +339,43 @@ func (d *DB) SearchInscriptions(params *FindProtocolsParams) (list []*tables.Ins
 		}
 		return
 	}
-	err = db.Offset((params.Page - 1) * params.Limit).Limit(params.Limit).Find(&list).Error
+	err = db.Select("inscriptions.*").
+		Offset((params.Page - 1) * params.Limit).
+		Limit(params.Limit).
+		Find(&list).Error
 	return
 }
 
-// SqlFix replaces the <binary> placeholder in a SQL string with the provided arguments.
-func SqlFix(sql string, args ...string) string {
-	for _, arg := range args {
-		if !strings.HasPrefix(arg, "0x") {
-			arg = "0x" + arg
-		}
-		sql = strings.Replace(sql, "<binary>", arg, 1)
-	}
-	return sql
+// InscriptionCounts are the numbers of inscriptions the indexer keeps in the statistic counters.
+type InscriptionCounts struct {
+	Blessed uint64 `gorm:"column:blessed"`
+	Cursed  uint64 `gorm:"column:cursed"`
+	Unbound uint64 `gorm:"column:unbound"`
+}
+
+// CountInscriptions counts the indexed inscriptions as the indexer counts them, mock inscriptions are not counted.
+// Blessed inscriptions have non-negative inscription numbers, cursed inscriptions negative ones,
+// and unbound inscriptions have the charm of the given flag.
+func (d *DB) CountInscriptions(unboundFlag uint16) (counts InscriptionCounts, err error) {
+	err = d.Model(&tables.Inscriptions{}).Select(
+		"COALESCE(sum(CASE WHEN inscription_num >= 0 THEN 1 ELSE 0 END), 0) AS blessed,"+
+			"COALESCE(sum(CASE WHEN inscription_num < 0 THEN 1 ELSE 0 END), 0) AS cursed,"+
+			"COALESCE(sum(CASE WHEN charms & ? <> 0 THEN 1 ELSE 0 END), 0) AS unbound",
+		unboundFlag,
+	).Where("sequence_num > 0").Scan(&counts).Error
+	return
+}
+
+// SequenceNums retrieves the sequence numbers of inscriptions after the given one in ascending order.
+func (d *DB) SequenceNums(after int64, limit int) (list []int64, err error) {
+	err = d.Model(&tables.Inscriptions{}).Where("sequence_num > ?", after).
+		Order("sequence_num").Limit(limit).Pluck("sequence_num", &list).Error
+	return
+}
+
+// DuplicateSequenceNums retrieves the sequence numbers used by more than one inscription.
+func (d *DB) DuplicateSequenceNums() (list []int64, err error) {
+	err = d.Model(&tables.Inscriptions{}).Group("sequence_num").Having("count(*) > 1").
+		Order("sequence_num").Pluck("sequence_num", &list).Error
+	return
 }
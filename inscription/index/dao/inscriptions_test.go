@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gotest.tools/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchInscriptionsOwner(t *testing.T) {
+	db, err := NewDB(WithBackend(BackendSqlite), WithPath(filepath.Join(t.TempDir(), "cins.db")), WithAutoMigrateTables(tables.Tables...))
+	assert.NilError(t, err)
+	for idx, owners := range [][2]string{{"alice", "alice"}, {"alice", "bob"}, {"bob", "alice"}} {
+		sequenceNum := int64(idx + 1)
+		assert.NilError(t, db.CreateInscription(&tables.Inscriptions{
+			InscriptionId: tables.InscriptionId{TxId: fmt.Sprintf("%064d", sequenceNum)},
+			SequenceNum:   sequenceNum,
+			Owner:         owners[0],
+			CurrentOwner:  owners[1],
+		}))
+	}
+	search := func(params *FindProtocolsParams) []int64 {
+		params.Page, params.Limit = 1, 10
+		list, total, err := db.SearchInscriptions(params)
+		assert.NilError(t, err)
+		assert.Equal(t, total, int64(len(list)))
+		sequenceNums := make([]int64, 0, len(list))
+		for _, v := range list {
+			sequenceNums = append(sequenceNums, v.SequenceNum)
+		}
+		return sequenceNums
+	}
+
+	// the owner is the current owner, the genesis owner is searched separately
+	assert.DeepEqual(t, search(&FindProtocolsParams{Owner: "alice"}), []int64{1, 3})
+	assert.DeepEqual(t, search(&FindProtocolsParams{GenesisOwner: "alice"}), []int64{1, 2})
+	assert.DeepEqual(t, search(&FindProtocolsParams{Owner: "bob", GenesisOwner: "alice"}), []int64{2})
+}
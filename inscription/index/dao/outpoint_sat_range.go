@@ -1,7 +1,6 @@
 package dao
 
 import (
-	"encoding/hex"
 	"errors"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"gorm.io/gorm"
@@ -11,15 +10,13 @@ import (
 // It takes a map where the keys are outpoints and the values are the corresponding satoshi ranges.
 // It returns any error encountered during the operation.
 func (d *DB) SetOutpointToSatRange(height uint32, satRanges ...*tables.OutpointSatRange) (err error) {
-	if err := d.CreateInBatches(&satRanges, 10_000).Error; err != nil {
+	if len(satRanges) == 0 {
+		return nil
+	}
+	if err := d.CreateInBatches(&satRanges, d.batchSize()).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Delete(&satRanges)
-		}),
-	}).Error
+	return d.undoInsert(height, satRanges)
 }
 
 // OutpointToSatRanges returns the satoshi ranges for a given outpoint.
@@ -44,14 +41,6 @@ func (d *DB) DelSatRangesByOutpoint(height uint32, outpoint string) (satRange ta
 	if err = d.Delete(&satRange).Error; err != nil {
 		return
 	}
-
-	sql := d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-		return tx.Create(&satRange)
-	})
-	sql = SqlFix(sql, hex.EncodeToString(satRange.SatRange))
-	err = d.Create(&tables.UndoLog{
-		Height: height,
-		Sql:    sql,
-	}).Error
+	err = d.undoDelete(height, &satRange)
 	return
 }
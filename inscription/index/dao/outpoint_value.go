@@ -1,10 +1,7 @@
 package dao
 
 import (
-	"github.com/gogf/gf/v2/util/gutil"
 	"github.com/inscription-c/cins/inscription/index/tables"
-	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 // GetValueByOutpoint retrieves the value associated with a given outpoint.
@@ -26,19 +23,16 @@ func (d *DB) DeleteValueByOutpoint(height uint32, outpoints ...string) (err erro
 		return
 	}
 	list := make([]*tables.OutpointValue, 0, len(outpoints))
-	err = d.Clauses(clause.Returning{}).Where("outpoint in (?)", outpoints).Delete(&list).Error
-	if err != nil {
+	if err = d.Where("outpoint in (?)", outpoints).Find(&list).Error; err != nil {
 		return
 	}
 	if len(list) == 0 {
 		return
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.CreateInBatches(list, 10_000)
-		}),
-	}).Error
+	if err = d.Delete(&list).Error; err != nil {
+		return
+	}
+	return d.undoDelete(height, list)
 }
 
 // SetOutpointToValue sets the values for a set of outpoints.
@@ -55,13 +49,8 @@ func (d *DB) SetOutpointToValue(height uint32, values map[string]int64) (err err
 			Value:    value,
 		})
 	}
-	if err := d.CreateInBatches(&list, 10_000).Error; err != nil {
+	if err := d.CreateInBatches(&list, d.batchSize()).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Where("outpoint in (?)", gutil.Keys(values)).Delete(&tables.OutpointValue{})
-		}),
-	}).Error
+	return d.undoInsert(height, list)
 }
@@ -2,7 +2,6 @@ package dao
 
 import (
 	"errors"
-	"github.com/inscription-c/cins/constants"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"gorm.io/gorm"
 )
@@ -21,22 +20,12 @@ func (d *DB) SaveProtocol(height uint32, protocol *tables.Protocol) error {
 		if err := d.Save(protocol).Error; err != nil {
 			return err
 		}
-		return d.Create(&tables.UndoLog{
-			Height: height,
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				return tx.Save(old)
-			}),
-		}).Error
+		return d.undoUpdate(height, old)
 	}
 	if err := d.Create(protocol).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Delete(protocol)
-		}),
-	}).Error
+	return d.undoInsert(height, protocol)
 }
 
 func (d *DB) DeleteMockProtocol() error {
@@ -46,11 +35,11 @@ func (d *DB) DeleteMockProtocol() error {
 // FindProtocol finds protocols based on the provided parameters.
 // It returns a list of matching protocols and any error encountered.
 func (d *DB) FindProtocol(protocol, ticker, operator string, tkid ...string) (list []*tables.Protocol, err error) {
-	db := d.DB
+	db := d.Where("protocol=? and ticker=? and operator=?", protocol, ticker, operator)
 	if len(tkid) > 0 {
-		db = d.Where("tkid=?", tkid[0])
+		db = db.Where("tkid=?", tkid[0])
 	}
-	err = db.Where("protocol=? and ticker=? and operator=?", protocol, ticker, operator).Find(&list).Error
+	err = db.Find(&list).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 	}
@@ -60,11 +49,12 @@ func (d *DB) FindProtocol(protocol, ticker, operator string, tkid ...string) (li
 // SumProtocolAmount counts the total amount for a specific protocol.
 // It returns the total amount and any error encountered.
 func (d *DB) SumProtocolAmount(protocol, ticker, operator string, tkid ...string) (total uint64, err error) {
-	db := d.DB
+	db := d.Model(&tables.Protocol{}).Select("COALESCE(sum(amount), 0)").
+		Where("protocol=? and ticker=? and operator=?", protocol, ticker, operator)
 	if len(tkid) > 0 {
-		db = d.Where("tkid=?", tkid[0])
+		db = db.Where("tkid=?", tkid[0])
 	}
-	err = db.Model(&tables.Protocol{}).Select("sum(amount)").Where("protocol=? and ticker=? and operator=?", protocol, ticker, operator).Scan(&total).Error
+	err = db.Scan(&total).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 	}
@@ -81,20 +71,6 @@ func (d *DB) GetProtocolByInscriptionId(inscriptionId *tables.InscriptionId) (p
 	return
 }
 
-// SumAddressNum counts the number of distinct addresses for a specific protocol.
-// It returns the total count and any error encountered.
-func (d *DB) SumAddressNum(protocol, ticker, operator string, tkid ...string) (total uint64, err error) {
-	db := d.DB
-	if len(tkid) > 0 {
-		db = d.Where("tkid=?", tkid[0])
-	}
-	err = db.Model(&tables.Protocol{}).Select("count(distinct COALESCE(`to`, miner))").Where("protocol=? and ticker=? and operator=?", protocol, ticker, operator).Scan(&total).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		err = nil
-	}
-	return
-}
-
 // FindTokenPageByTicker retrieves a page of tokens by ticker for a specific protocol.
 // It returns a list of tokens and any error encountered.
 func (d *DB) FindTokenPageByTicker(protocol, ticker, operator string, page, pageSize int) (list []*tables.Protocol, err error) {
@@ -106,24 +82,11 @@ func (d *DB) FindTokenPageByTicker(protocol, ticker, operator string, page, page
 	return
 }
 
-// SumMintAmountByAddress sums the amount for a specific address and protocol.
-// It returns a list of protocol amounts and any error encountered.
-func (d *DB) SumMintAmountByAddress(address, protocol string, page, pageSize int) (list []*tables.ProtocolAmount, err error) {
-	err = d.Model(&tables.Protocol{}).Select("tx_id, ticker, sum(amount) as amount").
-		Where("(`to`=? or miner=?) and protocol=? and operator=?", address, address, protocol, constants.OperationMint).
-		Group("`to`,miner").Offset((page - 1) * pageSize).Limit(pageSize + 1).Find(&list).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		err = nil
-	}
-	return
-}
-
 // FindMintHistoryByTkIdResp is a struct that represents the response for finding mint history by TkId.
 type FindMintHistoryByTkIdResp struct {
 	tables.InscriptionId `gorm:"embedded" json:"inscription_id"`
 	Amount               uint64 `gorm:"column:amount" json:"amount"` // The amount of the mint
-	To                   string `gorm:"column:to" json:"to_address"` // The address to which the mint was made
-	Miner                string `gorm:"column:miner" json:"miner"`   // The miner of the mint
+	Miner                string `gorm:"column:owner" json:"miner"`   // The miner of the mint
 }
 
 // FindMintHistoryByTkId finds the mint history for a specific TkId and protocol.
@@ -136,21 +99,3 @@ func (d *DB) FindMintHistoryByTkId(tkid, protocol, operator string, page, pageSi
 	}
 	return
 }
-
-// FindHoldersByTkIdResp is a struct that represents the response for finding holders by TkId.
-type FindHoldersByTkIdResp struct {
-	Amount  uint64 `gorm:"column:amount" json:"amount"`   // The amount held by the holder
-	Address string `gorm:"column:address" json:"address"` // The address of the holder
-}
-
-// FindHoldersByTkId finds the holders for a specific TkId and protocol.
-// It returns a list of holders and any error encountered.
-func (d *DB) FindHoldersByTkId(tkid, protocol, operator string, page, pageSize int) (list []*FindHoldersByTkIdResp, err error) {
-	err = d.Model(&tables.Protocol{}).Select("DISTINCT COALESCE(`to`,miner) as address, sum(amount) as amount").
-		Where("tkid=? and protocol=? and operator=?", tkid, protocol, operator).
-		Group("address").Offset((page - 1) * pageSize).Limit(pageSize + 1).Find(&list).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		err = nil
-	}
-	return
-}
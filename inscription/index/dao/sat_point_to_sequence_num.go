@@ -4,27 +4,23 @@ import (
 	"errors"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 // DeleteBySatPoint deletes records by a given SatPoint.
 // It takes a SatPoint as a parameter.
 // It returns any error encountered during the operation.
 func (d *DB) DeleteBySatPoint(height uint32, satpoint *tables.SatPointToSequenceNum) error {
-	if err := d.Clauses(clause.Returning{}).
-		Where("outpoint = ? AND offset = ?", satpoint.Outpoint, satpoint.Offset).
-		Delete(satpoint).Error; err != nil {
+	list := make([]*tables.SatPointToSequenceNum, 0)
+	if err := d.Where("outpoint = ? AND offset = ?", satpoint.Outpoint, satpoint.Offset).Find(&list).Error; err != nil {
 		return err
 	}
-	if satpoint.Id > 0 {
-		return d.Create(&tables.UndoLog{
-			Height: height,
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				return tx.Create(satpoint)
-			}),
-		}).Error
+	if len(list) == 0 {
+		return nil
 	}
-	return nil
+	if err := d.Delete(&list).Error; err != nil {
+		return err
+	}
+	return d.undoDelete(height, list)
 }
 
 // SetSatPointToSequenceNum sets a SatPoint to a sequence number in the database.
@@ -45,20 +41,12 @@ func (d *DB) SetSatPointToSequenceNum(height uint32, satPoint *tables.SatPointTo
 		if err := d.Save(satPoint).Error; err != nil {
 			return err
 		}
-		return d.Create(&tables.UndoLog{
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				return tx.Save(old)
-			}),
-		}).Error
+		return d.undoUpdate(height, old)
 	}
 	if err := d.Create(satPoint).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Delete(satPoint)
-		}),
-	}).Error
+	return d.undoInsert(height, satPoint)
 }
 
 // GetSatPointBySat retrieves a SatSatPoint by a given SAT.
@@ -71,3 +59,45 @@ func (d *DB) GetSatPointBySat(sat uint64) (res tables.SatSatPoint, err error) {
 	}
 	return
 }
+
+// GetSatPointBySequenceNum retrieves the current satpoint of an inscription by its sequence number.
+// It returns a SatPointToSequenceNum and any error encountered.
+func (d *DB) GetSatPointBySequenceNum(sequenceNum int64) (res tables.SatPointToSequenceNum, err error) {
+	err = d.DB.Where("sequence_num = ?", sequenceNum).First(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// SatPointOutpoints retrieves the distinct outpoints holding inscriptions after the given outpoint in ascending order.
+func (d *DB) SatPointOutpoints(after string, limit int) (list []string, err error) {
+	err = d.Model(&tables.SatPointToSequenceNum{}).Distinct("outpoint").Where("outpoint > ?", after).
+		Order("outpoint").Limit(limit).Pluck("outpoint", &list).Error
+	return
+}
+
+// OutpointInscription is an inscription id together with the outpoint currently holding it.
+type OutpointInscription struct {
+	tables.InscriptionId
+	Outpoint  string
+	SatOffset uint64 // offset of the inscription in the outpoint
+}
+
+// InscriptionsByOutpoints retrieves the ids of the inscriptions currently held by the given outpoints.
+// Outpoints without inscriptions are omitted from the result.
+func (d *DB) InscriptionsByOutpoints(outpoints []string) (list []*OutpointInscription, err error) {
+	if len(outpoints) == 0 {
+		return
+	}
+	err = d.Model(&tables.SatPointToSequenceNum{}).
+		Select("sat_point_to_sequence_num.outpoint, sat_point_to_sequence_num.offset AS sat_offset, inscriptions.tx_id, inscriptions.offset").
+		Joins("JOIN inscriptions ON inscriptions.sequence_num=sat_point_to_sequence_num.sequence_num").
+		Where("sat_point_to_sequence_num.outpoint in (?)", outpoints).
+		Order("sat_point_to_sequence_num.outpoint, sat_point_to_sequence_num.offset").
+		Scan(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
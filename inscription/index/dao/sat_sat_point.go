@@ -19,20 +19,10 @@ func (d *DB) SatToSatPoint(height uint32, satSatPoint *tables.SatSatPoint) error
 		if err := d.Save(satSatPoint).Error; err != nil {
 			return err
 		}
-		return d.Create(&tables.UndoLog{
-			Height: height,
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				return tx.Save(old)
-			}),
-		}).Error
+		return d.undoUpdate(height, old)
 	}
 	if err := d.Create(satSatPoint).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Delete(satSatPoint)
-		}),
-	}).Error
+	return d.undoInsert(height, satSatPoint)
 }
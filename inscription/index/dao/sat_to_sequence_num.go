@@ -19,18 +19,11 @@ func (d *DB) SaveSatToSequenceNumber(height uint32, sat uint64, sequenceNum int6
 		if old.SequenceNum == sequenceNum {
 			return nil
 		}
-		oldSequenceNum := old.SequenceNum
-		old.SequenceNum = sequenceNum
-		if err := d.Save(old).Error; err != nil {
+		if err := d.undoUpdate(height, old); err != nil {
 			return err
 		}
-		return d.Create(&tables.UndoLog{
-			Height: height,
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				old.SequenceNum = oldSequenceNum
-				return tx.Save(old)
-			}),
-		}).Error
+		old.SequenceNum = sequenceNum
+		return d.Save(old).Error
 	}
 
 	old.Sat = sat
@@ -38,10 +31,18 @@ func (d *DB) SaveSatToSequenceNumber(height uint32, sat uint64, sequenceNum int6
 	if err := d.Create(old).Error; err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Delete(old)
-		}),
-	}).Error
+	return d.undoInsert(height, old)
+}
+
+// FindInscriptionsBySat finds the inscriptions on a sat, oldest first.
+// Sats are only recorded when the index tracks sats, so the list is empty otherwise.
+// It returns a list of inscriptions and any error encountered.
+func (d *DB) FindInscriptionsBySat(sat uint64) (list []*tables.Inscriptions, err error) {
+	err = d.Select("id, tx_id, offset, sequence_num, inscription_num").
+		Where("sat=? and exists (select 1 from sat_to_sequence_num where sat_to_sequence_num.sat=?)", sat, sat).
+		Order("sequence_num").Find(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
 }
@@ -13,7 +13,7 @@ import (
 // If it is, it assigns nil to the error, effectively ignoring the error.
 // The method then returns the list of savepoints and the error.
 func (d *DB) ListSavepoint() (list []*tables.SavePoint, err error) {
-	err = d.Find(&list).Error
+	err = d.Order("id").Find(&list).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		err = nil
 	}
@@ -32,11 +32,47 @@ func (d *DB) OldestSavepoint() (savepoint *tables.SavePoint, err error) {
 	return
 }
 
-// DeleteSavepoint is a method that deletes all savepoints from the database.
+// CreateSavepoint creates a savepoint at a height, the undo logs after the undo log
+// with the given id roll the index back to it.
+func (d *DB) CreateSavepoint(height uint32, undoLogId uint64) error {
+	return d.Create(&tables.SavePoint{
+		Height:    height,
+		UndoLogId: undoLogId,
+	}).Error
+}
+
+// ExpireSavepoints deletes the savepoints with the given ids and the undo logs up to
+// the undo log with the given id, which only rolled the index back to them.
+func (d *DB) ExpireSavepoints(ids []uint64, undoLogId uint64) error {
+	if len(ids) > 0 {
+		if err := d.Where("id IN ?", ids).Delete(&tables.SavePoint{}).Error; err != nil {
+			return err
+		}
+	}
+	return d.Where("id<=?", undoLogId).Delete(&tables.UndoLog{}).Error
+}
+
+// DeleteSavepoint is a method that deletes the savepoints created after the savepoint with the given id.
 // It returns an error.
-// The method creates a new SavePoint struct and uses its TableName method to get the name of the table.
-// It then uses the Exec method of the DB struct to execute a SQL delete statement on the table.
-// If an error occurs during the deletion, it returns the error.
 func (d *DB) DeleteSavepoint(id uint64) error {
 	return d.Where("id>?", id).Delete(&tables.SavePoint{}).Error
 }
+
+// SavepointAtHeight retrieves the newest savepoint at or below a height.
+// The id of the savepoint is 0 if there is none.
+func (d *DB) SavepointAtHeight(height uint32) (savepoint tables.SavePoint, err error) {
+	err = d.Where("height <= ?", height).Order("id desc").First(&savepoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	return
+}
+
+// RollbackToSavepoint rolls the index back to the state it was in when a savepoint was created.
+// The undo logs and the savepoints after it are deleted.
+func (d *DB) RollbackToSavepoint(savepoint *tables.SavePoint) error {
+	if err := d.RollbackUndoLog(savepoint.UndoLogId); err != nil {
+		return err
+	}
+	return d.DeleteSavepoint(savepoint.Id)
+}
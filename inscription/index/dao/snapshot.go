@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"context"
+	"reflect"
+)
+
+// ExportTable reads all rows of the table of a model in batches ordered by primary key.
+// Each batch is passed to fn as a pointer to a slice of pointers to rows.
+func (d *DB) ExportTable(model interface{}, batchSize int, fn func(rows interface{}) error) error {
+	s, err := d.schema(model)
+	if err != nil {
+		return err
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+	sliceType := reflect.SliceOf(reflect.TypeOf(model))
+
+	var last interface{} = 0
+	for {
+		rows := reflect.New(sliceType)
+		if err := d.Model(model).Where(pk+" > ?", last).Order(pk).Limit(batchSize).Find(rows.Interface()).Error; err != nil {
+			return err
+		}
+		n := rows.Elem().Len()
+		if n == 0 {
+			return nil
+		}
+		if err := fn(rows.Interface()); err != nil {
+			return err
+		}
+		if n < batchSize {
+			return nil
+		}
+		last, _ = s.PrioritizedPrimaryField.ValueOf(context.Background(), reflect.Indirect(rows.Elem().Index(n-1)))
+	}
+}
+
+// ImportRows inserts rows keeping their primary keys.
+// The rows are a pointer to a slice of pointers to rows of the same table.
+func (d *DB) ImportRows(rows interface{}) error {
+	if reflect.Indirect(reflect.ValueOf(rows)).Len() == 0 {
+		return nil
+	}
+	return d.CreateInBatches(rows, d.batchSize()).Error
+}
+
+// TablesEmpty returns whether the tables of all models are empty.
+func (d *DB) TablesEmpty(models ...interface{}) (empty bool, err error) {
+	for _, model := range models {
+		var count int64
+		if err = d.Model(model).Limit(1).Count(&count).Error; err != nil {
+			return
+		}
+		if count > 0 {
+			return
+		}
+	}
+	empty = true
+	return
+}
@@ -36,24 +36,13 @@ func (d *DB) IncrementStatistic(height uint32, name tables.StatisticType, count
 		if err := d.Create(statistic).Error; err != nil {
 			return err
 		}
-		return d.Create(&tables.UndoLog{
-			Height: height,
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				return tx.Delete(statistic)
-			}),
-		}).Error
+		return d.undoInsert(height, statistic)
 	}
-	statistic.Count += count
-	if err := d.Save(statistic).Error; err != nil {
+	if err := d.undoUpdate(height, statistic); err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			statistic.Count -= count
-			return tx.Save(statistic)
-		}),
-	}).Error
+	statistic.Count += count
+	return d.Save(statistic).Error
 }
 
 // SetStatistic sets the count of a specific statistic to a given amount.
@@ -69,26 +58,14 @@ func (d *DB) SetStatistic(height uint32, name tables.StatisticType, count uint64
 		if err := d.Create(statistic).Error; err != nil {
 			return err
 		}
-		return d.Create(&tables.UndoLog{
-			Height: height,
-			Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-				return tx.Delete(statistic)
-			}),
-		}).Error
+		return d.undoInsert(height, statistic)
 	}
 	if statistic.Count == count {
 		return nil
 	}
-	oldCont := statistic.Count
-	statistic.Count = count
-	if err := d.Save(statistic).Error; err != nil {
+	if err := d.undoUpdate(height, statistic); err != nil {
 		return err
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql: d.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			statistic.Count = oldCont
-			return tx.Save(statistic)
-		}),
-	}).Error
+	statistic.Count = count
+	return d.Save(statistic).Error
 }
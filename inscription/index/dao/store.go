@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/inscription/index/model"
+	"github.com/inscription-c/cins/inscription/index/tables"
+)
+
+// Store is the storage of the index, implemented by DB over the storage backends.
+// The indexer and the API read and write the index through it, only the owner of
+// a DB opens and ends its transactions.
+type Store interface {
+	// balances
+	GetBalance(tkid, address string) (balance tables.Balance, err error)
+	UpdateBalance(height uint32, tkid, ticker, address string, available, transferable int64) error
+	FindBalancesByAddress(address string) (list []*tables.Balance, err error)
+	FindHoldersByTkId(tkid string, page, pageSize int) (list []*tables.Balance, err error)
+	CountHoldersByTkId(tkid string) (total int64, err error)
+
+	// blocks
+	BlockHeader() (height uint32, header *wire.BlockHeader, err error)
+	BlockHash(height ...uint32) (blockHash string, err error)
+	BlockHeight() (height uint32, err error)
+	BlockCount() (count uint32, err error)
+	SaveBlockInfo(block *tables.BlockInfo) error
+	DeleteBlockInfoByHeight(height uint32) (info tables.BlockInfo, err error)
+	DeleteBlocksAfter(height uint32) error
+	BlockRows(from, to uint32) (map[string][]map[string]interface{}, error)
+
+	// inscriptions
+	CreateInscriptionParent(height uint32, sequenceNum, parentSequenceNum int64) error
+	FindParentsBySequenceNum(sequenceNum int64) (list []*tables.InscriptionId, err error)
+	FindChildrenBySequenceNum(sequenceNum int64, page, size int) (list []*tables.InscriptionId, err error)
+	CreateInscriptionTransfer(height uint32, transfer *tables.InscriptionTransfer) error
+	FindTransfersBySequenceNum(sequenceNum int64, page, size int) (list []*tables.InscriptionTransfer, err error)
+	NextSequenceNumber() (num int64, err error)
+	GetInscriptionById(inscriptionId *tables.InscriptionId) (ins tables.Inscriptions, err error)
+	GetInscriptionByOutpoint(outpoint *model.OutPoint) (list []*tables.InscriptionId, err error)
+	InscriptionsByOutpoint(outpoint string) (res []*Inscription, err error)
+	DeleteInscriptionById(height uint32, inscriptionId *tables.InscriptionId) (sequenceNum int64, err error)
+	CreateInscription(ins *tables.Inscriptions) error
+	UpdateInscriptionOwner(height uint32, sequenceNum int64, owner string) error
+	FindInscriptionsByCurrentOwner(owner string, page, size int) (list []*AddressInscription, err error)
+	DeleteMockInscriptions() error
+	GetInscriptionByInscriptionNum(inscriptionNum int64) (ins tables.Inscriptions, err error)
+	GetInscriptionBySequenceNum(sequenceNum int64) (ins tables.Inscriptions, err error)
+	FindInscriptionsByPage(page, size int) (list []*tables.InscriptionId, err error)
+	FindInscriptionsInBlockPage(height, page, size int) (list []*model.OutPoint, err error)
+	FindInscriptionsInBlock(height uint32) (list []*model.OutPoint, err error)
+	InscriptionsNum() (total int64, err error)
+	InscriptionsStoredData() (total uint64, err error)
+	InscriptionsTotalFees() (total uint64, err error)
+	FirstInscriptionByOwner(owner string) (firts tables.Inscriptions, err error)
+	SearchInscriptions(params *FindProtocolsParams) (list []*tables.Inscriptions, total int64, err error)
+	CountInscriptions(unboundFlag uint16) (counts InscriptionCounts, err error)
+	SequenceNums(after int64, limit int) (list []int64, err error)
+	DuplicateSequenceNums() (list []int64, err error)
+
+	// outpoints and sats
+	SetOutpointToSatRange(height uint32, satRanges ...*tables.OutpointSatRange) (err error)
+	OutpointToSatRanges(outpoint string) (satRange tables.OutpointSatRange, err error)
+	DelSatRangesByOutpoint(height uint32, outpoint string) (satRange tables.OutpointSatRange, err error)
+	GetValueByOutpoint(outpoint string) (value int64, err error)
+	DeleteValueByOutpoint(height uint32, outpoints ...string) (err error)
+	SetOutpointToValue(height uint32, values map[string]int64) (err error)
+	DeleteBySatPoint(height uint32, satpoint *tables.SatPointToSequenceNum) error
+	SetSatPointToSequenceNum(height uint32, satPoint *tables.SatPointToSequenceNum) error
+	GetSatPointBySat(sat uint64) (res tables.SatSatPoint, err error)
+	GetSatPointBySequenceNum(sequenceNum int64) (res tables.SatPointToSequenceNum, err error)
+	SatPointOutpoints(after string, limit int) (list []string, err error)
+	InscriptionsByOutpoints(outpoints []string) (list []*OutpointInscription, err error)
+	SatToSatPoint(height uint32, satSatPoint *tables.SatSatPoint) error
+	SaveSatToSequenceNumber(height uint32, sat uint64, sequenceNum int64) error
+	FindInscriptionsBySat(sat uint64) (list []*tables.Inscriptions, err error)
+
+	// protocols
+	SaveProtocol(height uint32, protocol *tables.Protocol) error
+	DeleteMockProtocol() error
+	FindProtocol(protocol, ticker, operator string, tkid ...string) (list []*tables.Protocol, err error)
+	SumProtocolAmount(protocol, ticker, operator string, tkid ...string) (total uint64, err error)
+	GetProtocolByInscriptionId(inscriptionId *tables.InscriptionId) (p tables.Protocol, err error)
+	FindTokenPageByTicker(protocol, ticker, operator string, page, pageSize int) (list []*tables.Protocol, err error)
+	FindMintHistoryByTkId(tkid, protocol, operator string, page, pageSize int) (list []*FindMintHistoryByTkIdResp, err error)
+
+	// savepoints and undo logs
+	ListSavepoint() (list []*tables.SavePoint, err error)
+	OldestSavepoint() (savepoint *tables.SavePoint, err error)
+	CreateSavepoint(height uint32, undoLogId uint64) error
+	ExpireSavepoints(ids []uint64, undoLogId uint64) error
+	DeleteSavepoint(id uint64) error
+	SavepointAtHeight(height uint32) (savepoint tables.SavePoint, err error)
+	RollbackToSavepoint(savepoint *tables.SavePoint) error
+	LatestUndoLogId() (id uint64, err error)
+	RollbackUndoLog(afterId uint64) error
+	DeleteUndoLog() error
+
+	// snapshots
+	ExportTable(model interface{}, batchSize int, fn func(rows interface{}) error) error
+	ImportRows(rows interface{}) error
+	TablesEmpty(models ...interface{}) (empty bool, err error)
+
+	// statistics
+	GetStatisticCountByName(name tables.StatisticType) (count uint64, err error)
+	IncrementStatistic(height uint32, name tables.StatisticType, count uint64) error
+	SetStatistic(height uint32, name tables.StatisticType, count uint64) error
+}
+
+var _ Store = (*DB)(nil)
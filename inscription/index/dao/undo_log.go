@@ -1,45 +1,177 @@
 package dao
 
 import (
-	"database/sql"
+	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/inscription-c/cins/inscription/index/tables"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"slices"
 )
 
-// AddUndoLog is a method that adds an undo log to the database.
-// It takes two parameters: height of type uint32 and sql of type string.
-// The height parameter represents the height of the undo log.
-// The sql parameter represents the SQL statement of the undo log.
-//
-// The method first calls the OldestSavepoint method to get the oldest savepoint.
-// If an error occurs during this operation, it returns the error.
-// If the ID of the oldest savepoint is 0, it returns nil.
-//
-// The method then creates a new UndoLog struct with the provided height and SQL statement,
-// and calls the Create method to add the undo log to the database.
-// If an error occurs during this operation, it returns the error.
-//
-// The method returns an error if any operation fails, or nil if the operations succeed.
-func (d *DB) AddUndoLog(height uint32, sql string) error {
-	oldest, err := d.OldestSavepoint()
+// undoLogBatchSize is the number of undo logs written or rolled back at once.
+const undoLogBatchSize = 1000
+
+// undoInsert records rows inserted at the given height in the undo log.
+// The rows are a pointer to a row or a slice of pointers to rows of the same table.
+func (d *DB) undoInsert(height uint32, rows interface{}) error {
+	return d.addUndoLog(height, tables.UndoOperationInsert, rows)
+}
+
+// undoUpdate records the before-image of rows updated at the given height in the undo log.
+// If columns are given, only these columns are recorded and restored on rollback.
+func (d *DB) undoUpdate(height uint32, rows interface{}, columns ...string) error {
+	return d.addUndoLog(height, tables.UndoOperationUpdate, rows, columns...)
+}
+
+// undoDelete records the before-image of rows deleted at the given height in the undo log.
+func (d *DB) undoDelete(height uint32, rows interface{}) error {
+	return d.addUndoLog(height, tables.UndoOperationDelete, rows)
+}
+
+// addUndoLog records a change of rows in the undo log.
+// Inserts only record the primary key, updates and deletes also record the before-image of the row.
+func (d *DB) addUndoLog(height uint32, operation tables.UndoOperation, rows interface{}, columns ...string) error {
+	values := make([]reflect.Value, 0)
+	rv := reflect.ValueOf(rows)
+	if list := reflect.Indirect(rv); list.Kind() == reflect.Slice {
+		for i := 0; i < list.Len(); i++ {
+			values = append(values, list.Index(i))
+		}
+	} else {
+		values = append(values, rv)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	s, err := d.schema(values[0].Interface())
 	if err != nil {
 		return err
 	}
-	if oldest.Id == 0 {
-		return nil
+	ctx := context.Background()
+	logs := make([]*tables.UndoLog, 0, len(values))
+	for _, v := range values {
+		v = reflect.Indirect(v)
+		pk, _ := s.PrioritizedPrimaryField.ValueOf(ctx, v)
+		undoLog := &tables.UndoLog{
+			Height:     height,
+			Table:      s.Table,
+			Operation:  operation,
+			PrimaryKey: pk.(uint64),
+		}
+		if operation != tables.UndoOperationInsert {
+			image := make(map[string]interface{})
+			for _, field := range s.Fields {
+				if field.DBName == "" || (len(columns) > 0 && !slices.Contains(columns, field.DBName)) {
+					continue
+				}
+				image[field.DBName], _ = field.ValueOf(ctx, v)
+			}
+			data, err := json.Marshal(image)
+			if err != nil {
+				return err
+			}
+			undoLog.BeforeImage = string(data)
+		}
+		logs = append(logs, undoLog)
+	}
+	return d.CreateInBatches(logs, undoLogBatchSize).Error
+}
+
+// LatestUndoLogId returns the id of the latest undo log, 0 if there is none.
+func (d *DB) LatestUndoLogId() (id uint64, err error) {
+	err = d.Model(&tables.UndoLog{}).Select("COALESCE(max(id), 0)").Scan(&id).Error
+	return
+}
+
+// RollbackUndoLog rolls back the changes recorded in the undo logs after the given undo log id, newest first.
+// The rolled back undo logs are deleted.
+func (d *DB) RollbackUndoLog(afterId uint64) error {
+	for {
+		list := make([]*tables.UndoLog, 0, undoLogBatchSize)
+		if err := d.Where("id > ?", afterId).Order("id desc").Limit(undoLogBatchSize).Find(&list).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		for _, undoLog := range list {
+			if err := d.applyUndoLog(undoLog); err != nil {
+				return fmt.Errorf("undo log %d: %w", undoLog.Id, err)
+			}
+		}
+		if err := d.Where("id >= ?", list[len(list)-1].Id).Delete(&tables.UndoLog{}).Error; err != nil {
+			return err
+		}
 	}
-	return d.Create(&tables.UndoLog{
-		Height: height,
-		Sql:    sql,
-	}).Error
 }
 
-// FindUndoLog is a method that retrieves all undo logs from the database in descending order of their IDs.
-// It returns a slice of pointers to UndoLog structs and an error.
-// The method uses the Order method of the DB struct to order the undo logs by their IDs in descending order.
-// It then uses the Find method of the DB struct to retrieve the undo logs and assigns them to undoLogs.
-// If an error occurs during the retrieval, it returns the undoLogs and the error.
-func (d *DB) FindUndoLog() (*sql.Rows, error) {
-	return d.Model(&tables.UndoLog{}).Order("id desc").Rows()
+// applyUndoLog reverts the change recorded in an undo log.
+func (d *DB) applyUndoLog(undoLog *tables.UndoLog) error {
+	model := tables.NewModel(undoLog.Table)
+	if model == nil {
+		return fmt.Errorf("unknown table %s", undoLog.Table)
+	}
+	s, err := d.schema(model)
+	if err != nil {
+		return err
+	}
+	pk := s.PrioritizedPrimaryField.DBName
+
+	switch undoLog.Operation {
+	case tables.UndoOperationInsert:
+		return d.Where(pk+" = ?", undoLog.PrimaryKey).Delete(model).Error
+	case tables.UndoOperationUpdate:
+		values, err := beforeImage(s, undoLog)
+		if err != nil {
+			return err
+		}
+		delete(values, pk)
+		return d.Model(model).Where(pk+" = ?", undoLog.PrimaryKey).Updates(values).Error
+	case tables.UndoOperationDelete:
+		values, err := beforeImage(s, undoLog)
+		if err != nil {
+			return err
+		}
+		values[pk] = undoLog.PrimaryKey
+		return d.Model(model).Create(values).Error
+	default:
+		return fmt.Errorf("unknown operation %s", undoLog.Operation)
+	}
+}
+
+// beforeImage decodes the before-image of an undo log into column values of the types of the table schema.
+// Columns that no longer exist in the schema are skipped.
+func beforeImage(s *schema.Schema, undoLog *tables.UndoLog) (map[string]interface{}, error) {
+	image := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(undoLog.BeforeImage), &image); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(image))
+	for column, raw := range image {
+		field := s.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			continue
+		}
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return nil, fmt.Errorf("column %s: %w", column, err)
+		}
+		values[field.DBName] = value.Elem().Interface()
+	}
+	return values, nil
+}
+
+// schema returns the parsed schema of a model.
+func (d *DB) schema(model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: d.DB}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
 }
 
 // DeleteUndoLog is a method that deletes all undo logs from the database.
@@ -0,0 +1,176 @@
+package dao
+
+import (
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gotest.tools/assert"
+	"path/filepath"
+	"testing"
+)
+
+// testDB returns a SQLite database with empty index tables in a temporary directory.
+func testDB(t *testing.T) *DB {
+	db, err := NewDB(
+		WithBackend(BackendSqlite),
+		WithPath(filepath.Join(t.TempDir(), "cins.db")),
+		WithAutoMigrateTables(tables.Tables...),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// indexSyntheticBlock makes the changes indexing a block makes, inserting, updating and deleting rows of every kind.
+func indexSyntheticBlock(db *DB, height uint32) error {
+	return db.Transaction(func(tx *DB) error {
+		if err := tx.SaveBlockInfo(&tables.BlockInfo{
+			Height:    height,
+			Header:    []byte{byte(height), 0, 1},
+			Timestamp: int64(height),
+		}); err != nil {
+			return err
+		}
+
+		outpoint := fmt.Sprintf("%064d:0", height)
+		ins := &tables.Inscriptions{
+			InscriptionId: tables.InscriptionId{TxId: fmt.Sprintf("%064d", height)},
+			SequenceNum:   int64(height),
+			Owner:         "owner",
+			CurrentOwner:  "owner",
+			Height:        height,
+			Body:          []byte(fmt.Sprintf("body %d", height)),
+		}
+		if err := tx.CreateInscription(ins); err != nil {
+			return err
+		}
+		if err := tx.SetSatPointToSequenceNum(height, &tables.SatPointToSequenceNum{
+			Outpoint:    outpoint,
+			SequenceNum: int64(height),
+		}); err != nil {
+			return err
+		}
+		if err := tx.SetOutpointToValue(height, map[string]int64{outpoint: int64(height)}); err != nil {
+			return err
+		}
+		if err := tx.SetOutpointToSatRange(height, &tables.OutpointSatRange{
+			Outpoint: outpoint,
+			SatRange: []byte{byte(height)},
+		}); err != nil {
+			return err
+		}
+		if err := tx.IncrementStatistic(height, tables.StatisticCommits, 1); err != nil {
+			return err
+		}
+		if err := tx.SaveSatToSequenceNumber(height, uint64(height%2), int64(height)); err != nil {
+			return err
+		}
+		if err := tx.UpdateBalance(height, "tkid", "ticker", "address", 1, 0); err != nil {
+			return err
+		}
+
+		if height == 0 {
+			return nil
+		}
+		// Spend the output of the previous block.
+		prevOutpoint := fmt.Sprintf("%064d:0", height-1)
+		if err := tx.DeleteValueByOutpoint(height, prevOutpoint); err != nil {
+			return err
+		}
+		if _, err := tx.DelSatRangesByOutpoint(height, prevOutpoint); err != nil {
+			return err
+		}
+		if err := tx.DeleteBySatPoint(height, &tables.SatPointToSequenceNum{Outpoint: prevOutpoint}); err != nil {
+			return err
+		}
+		return tx.UpdateInscriptionOwner(height, int64(height-1), fmt.Sprintf("owner %d", height))
+	})
+}
+
+// createSavepoint creates a savepoint at a height.
+func createSavepoint(db *DB, height uint32) (*tables.SavePoint, error) {
+	latest, err := db.LatestUndoLogId()
+	if err != nil {
+		return nil, err
+	}
+	savepoint := &tables.SavePoint{Height: height, UndoLogId: latest}
+	return savepoint, db.Create(savepoint).Error
+}
+
+// snapshot returns the rows of the index tables ordered by id.
+func snapshot(t *testing.T, db *DB) map[string][]map[string]interface{} {
+	res := make(map[string][]map[string]interface{})
+	for _, table := range tables.Tables {
+		name := table.(interface{ TableName() string }).TableName()
+		if name == (&tables.UndoLog{}).TableName() || name == (&tables.SavePoint{}).TableName() {
+			continue
+		}
+		rows := make([]map[string]interface{}, 0)
+		if err := db.Table(name).Order("id").Find(&rows).Error; err != nil {
+			t.Fatal(err)
+		}
+		res[name] = rows
+	}
+	return res
+}
+
+func TestRollbackToSavepoint(t *testing.T) {
+	db := testDB(t)
+
+	for height := uint32(0); height <= 2; height++ {
+		assert.NilError(t, indexSyntheticBlock(db, height))
+	}
+	savepoint2, err := createSavepoint(db, 2)
+	assert.NilError(t, err)
+	snapshot2 := snapshot(t, db)
+
+	for height := uint32(3); height <= 4; height++ {
+		assert.NilError(t, indexSyntheticBlock(db, height))
+	}
+	savepoint4, err := createSavepoint(db, 4)
+	assert.NilError(t, err)
+	snapshot4 := snapshot(t, db)
+
+	for height := uint32(5); height <= 6; height++ {
+		assert.NilError(t, indexSyntheticBlock(db, height))
+	}
+
+	// The rows of the blocks after the newest savepoint are the rows of block 5 and 6.
+	rows, err := db.BlockRows(5, 6)
+	assert.NilError(t, err)
+	heights := make([]interface{}, 0)
+	for _, row := range rows[(&tables.BlockInfo{}).TableName()] {
+		heights = append(heights, row["height"])
+	}
+	assert.DeepEqual(t, heights, []interface{}{uint32(5), uint32(6)})
+
+	// Roll back the blocks after the newest savepoint.
+	assert.NilError(t, db.Transaction(func(tx *DB) error {
+		return tx.RollbackToSavepoint(savepoint4)
+	}))
+	assert.DeepEqual(t, snapshot(t, db), snapshot4)
+
+	// Reorg block 3 and 4, which requires rolling back to the older savepoint.
+	savepoint, err := db.SavepointAtHeight(2)
+	assert.NilError(t, err)
+	assert.Equal(t, savepoint.Id, savepoint2.Id)
+	assert.NilError(t, db.Transaction(func(tx *DB) error {
+		return tx.RollbackToSavepoint(&savepoint)
+	}))
+	assert.DeepEqual(t, snapshot(t, db), snapshot2)
+
+	savepoints, err := db.ListSavepoint()
+	assert.NilError(t, err)
+	assert.Equal(t, len(savepoints), 1)
+	latest, err := db.LatestUndoLogId()
+	assert.NilError(t, err)
+	assert.Equal(t, latest, savepoint2.UndoLogId)
+
+	// Index the new chain on top of the savepoint.
+	for height := uint32(3); height <= 4; height++ {
+		assert.NilError(t, indexSyntheticBlock(db, height))
+	}
+	count, err := db.BlockCount()
+	assert.NilError(t, err)
+	assert.Equal(t, count, uint32(5))
+}
@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gorm.io/gorm"
+)
+
+// upgrade converts the rows written by an older version of the index.
+type upgrade func(tx *gorm.DB) error
+
+// pendingUpgrades returns the upgrades of the rows of the index, found from the columns of the tables before they are
+// migrated. An upgrade runs once, as the migration or the upgrade changes the columns it is found from.
+func pendingUpgrades(db *gorm.DB) []upgrade {
+	var upgrades []upgrade
+	migrator := db.Migrator()
+	if migrator.HasTable(&tables.Inscriptions{}) && !migrator.HasColumn(&tables.Inscriptions{}, "current_owner") {
+		upgrades = append(upgrades, backfillCurrentOwner)
+	}
+	if migrator.HasTable(&tables.UndoLog{}) && migrator.HasColumn(&tables.UndoLog{}, "sql") {
+		upgrades = append(upgrades, dropSqlUndoLogs)
+	}
+	return upgrades
+}
+
+// runUpgrades runs the upgrades in a transaction, after the tables are migrated.
+func runUpgrades(db *gorm.DB, upgrades []upgrade) error {
+	if len(upgrades) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, fn := range upgrades {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// backfillCurrentOwner sets the current owner of the inscriptions indexed before it was tracked,
+// to the owner of their latest transfer, or to their genesis owner if no transfer was recorded.
+func backfillCurrentOwner(tx *gorm.DB) error {
+	transfers := tx.Model(&tables.InscriptionTransfer{}).Select("owner").
+		Where("sequence_num = inscriptions.sequence_num").Order("height desc, id desc").Limit(1)
+	return tx.Model(&tables.Inscriptions{}).Where("1 = 1").
+		Update("current_owner", gorm.Expr("COALESCE((?), owner)", transfers)).Error
+}
+
+// dropSqlUndoLogs deletes the undo logs written as sql statements, which can not be rolled back,
+// and the savepoints of the blocks, then drops the sql column. Without savepoints a reorg or a
+// re-index deletes the blocks after its height, see DeleteBlocksAfter.
+func dropSqlUndoLogs(tx *gorm.DB) error {
+	if err := tx.Where("table_name = ''").Delete(&tables.UndoLog{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("1 = 1").Delete(&tables.SavePoint{}).Error; err != nil {
+		return err
+	}
+	return tx.Migrator().DropColumn(&tables.UndoLog{}, "sql")
+}
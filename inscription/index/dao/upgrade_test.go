@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gotest.tools/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackfillCurrentOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cins.db")
+	db, err := NewDB(WithBackend(BackendSqlite), WithPath(path), WithAutoMigrateTables(tables.Tables...))
+	assert.NilError(t, err)
+	for sequenceNum, owners := range map[int64][]string{1: {"genesis 1"}, 2: {"genesis 2", "fee", "owner 2"}, 3: {"genesis 3", "fee"}} {
+		assert.NilError(t, db.CreateInscription(&tables.Inscriptions{
+			InscriptionId: tables.InscriptionId{TxId: fmt.Sprintf("%064d", sequenceNum)},
+			SequenceNum:   sequenceNum,
+			Owner:         owners[0],
+		}))
+		for height, owner := range owners {
+			if owner == "fee" {
+				owner = ""
+			}
+			assert.NilError(t, db.CreateInscriptionTransfer(uint32(height), &tables.InscriptionTransfer{
+				SequenceNum: sequenceNum,
+				TxId:        fmt.Sprintf("%064d", height),
+				Height:      uint32(height),
+				Owner:       owner,
+			}))
+		}
+	}
+
+	// An index of a version without the current owner is upgraded when it is opened.
+	assert.NilError(t, db.Migrator().DropIndex(&tables.Inscriptions{}, "CurrentOwner"))
+	assert.NilError(t, db.Migrator().DropColumn(&tables.Inscriptions{}, "current_owner"))
+	db, err = NewDB(WithBackend(BackendSqlite), WithPath(path), WithAutoMigrateTables(tables.Tables...))
+	assert.NilError(t, err)
+
+	var owners []string
+	assert.NilError(t, db.Model(&tables.Inscriptions{}).Order("sequence_num").Pluck("current_owner", &owners).Error)
+	assert.DeepEqual(t, owners, []string{"genesis 1", "owner 2", ""})
+}
+
+func TestDropSqlUndoLogs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cins.db")
+	db, err := NewDB(WithBackend(BackendSqlite), WithPath(path), WithAutoMigrateTables(tables.Tables...))
+	assert.NilError(t, err)
+	assert.NilError(t, db.Exec("ALTER TABLE `undo_log` ADD COLUMN `sql` text NOT NULL DEFAULT ''").Error)
+	assert.NilError(t, db.Exec("INSERT INTO undo_log (height, sql) VALUES (1, 'DELETE FROM inscriptions WHERE id = 1')").Error)
+	assert.NilError(t, db.Create(&tables.SavePoint{Height: 1, UndoLogId: 1}).Error)
+
+	// The undo logs of a version writing sql statements are deleted with their savepoints when the index is opened.
+	db, err = NewDB(WithBackend(BackendSqlite), WithPath(path), WithAutoMigrateTables(tables.Tables...))
+	assert.NilError(t, err)
+	assert.Assert(t, !db.Migrator().HasColumn(&tables.UndoLog{}, "sql"))
+	for _, table := range []interface{}{&tables.UndoLog{}, &tables.SavePoint{}} {
+		var count int64
+		assert.NilError(t, db.Model(table).Count(&count).Error)
+		assert.Equal(t, count, int64(0))
+	}
+}
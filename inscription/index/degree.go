@@ -1,6 +1,8 @@
 // Package index provides the implementation of Degree struct and its associated methods.
 package index
 
+import "fmt"
+
 // CycleEpochs is a constant that represents the number of epochs in a cycle.
 const CycleEpochs uint32 = 6
 
@@ -35,3 +37,8 @@ func NewDegreeFromSat(sat *Sat) *Degree {
 		third:  sat.Third(),                                     // Get the third part of the degree from the Sat
 	}
 }
+
+// String is a method that returns the degree notation of a Degree, such as 0°1′1″0‴.
+func (d *Degree) String() string {
+	return fmt.Sprintf("%d°%d′%d″%d‴", d.hour, d.minute, d.second, d.third)
+}
@@ -77,6 +77,30 @@ type Envelope struct {
 	payload *model.Inscription
 }
 
+// Curse returns the curse of the envelope that does not depend on the inscriptions
+// already indexed, or 0 if there is none. The re-inscription curse is checked by the updater.
+func (e *Envelope) Curse() Curse {
+	switch {
+	case e.payload.UnRecognizedEvenField:
+		return CurseUnrecognizedEvenField
+	case e.payload.DuplicateField:
+		return CurseDuplicateField
+	case e.payload.IncompleteField:
+		return CurseIncompleteField
+	case e.index != 0:
+		return CurseNotInFirstInput
+	case e.offset != 0:
+		return CurseNotAtOffsetZero
+	case len(e.payload.Pointer) > 0:
+		return CursePointer
+	case e.pushNum:
+		return CursePushNum
+	case e.stutter:
+		return CurseStutter
+	}
+	return 0
+}
+
 // Envelopes is a slice of pointers to Envelope.
 type Envelopes []*Envelope
 
@@ -111,7 +135,7 @@ func EnvelopeFromRawEnvelope(raw RawEnvelopes) Envelopes {
 // It then removes recognized fields from the map and assigns them to their respective variables.
 // It checks for unrecognized even fields in the remaining map.
 // Finally, it creates an Envelope with the input, offset, pushNum, stutter, and payload from the raw envelope and the fields from the map.
-// The payload of the Envelope is an Inscription that contains the body, contentEncoding, contentType, dstChain, metadata, pointer, and flags for unrecognized even field, duplicate field, and incomplete field.
+// The payload of the Envelope is an Inscription that contains the body, contentEncoding, contentType, delegate, dstChain, metadata, metaprotocol, parents, pointer, and flags for unrecognized even field, duplicate field, and incomplete field.
 func fromRawEnvelope(r *RawEnvelope) *Envelope {
 	// Find the index of the body in the payload of the raw envelope
 	bodyIdx := -1
@@ -171,8 +195,17 @@ func fromRawEnvelope(r *RawEnvelope) *Envelope {
 	contentEncoding := TagContentEncoding.RemoveField(fields)
 	contentType := TagContentType.RemoveField(fields)
 	metadata := TagMetadata.RemoveField(fields)
+	metaprotocol := TagMetaprotocol.RemoveField(fields)
 	pointer := TagPointer.RemoveField(fields)
 	cInsDescriptionData := TagCInsDescription.RemoveField(fields)
+	delegate := TagDelegate.RemoveField(fields)
+
+	parents := make([]*tables.InscriptionId, 0)
+	for _, v := range TagParent.RemoveArray(fields) {
+		if parent := tables.InscriptionIdFromBytes(v); parent != nil {
+			parents = append(parents, parent)
+		}
+	}
 
 	// Check for unrecognized even fields in the remaining map
 	unrecognizedEvenField := false
@@ -187,7 +220,10 @@ func fromRawEnvelope(r *RawEnvelope) *Envelope {
 		Body:                  body.Bytes(),
 		ContentEncoding:       contentEncoding,
 		ContentType:           constants.ContentType(contentType),
+		Delegate:              tables.InscriptionIdFromBytes(delegate),
 		Metadata:              metadata,
+		Metaprotocol:          string(metaprotocol),
+		Parents:               parents,
 		Pointer:               pointer,
 		UnRecognizedEvenField: unrecognizedEvenField,
 		DuplicateField:        duplicateField,
@@ -135,6 +135,11 @@ func NewEpochFromSat(sat Sat) *Epoch {
 	return e
 }
 
+// N returns the number of the epoch.
+func (e *Epoch) N() uint32 {
+	return e.epoch
+}
+
 // Subsidy returns the subsidy for the epoch.
 func (e *Epoch) Subsidy() uint64 {
 	if e.epoch < FirstPostSubsidy.epoch {
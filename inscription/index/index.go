@@ -14,7 +14,9 @@ import (
 	"github.com/inscription-c/cins/inscription/log"
 	"github.com/inscription-c/cins/pkg/signal"
 	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/cins/pkg/wallet/chain"
 	"golang.org/x/sync/errgroup"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -38,8 +40,25 @@ type Options struct {
 	noIndexInscriptions bool
 	// startHeight is an uint32 that represents the starting height of the blockchain to index from.
 	firstInscriptionHeight uint32
-	// limit the tidb tx session memory, default 3GB
-	tidbSessionMemLimit int
+
+	// blockNotify is the block notification source, BlockNotifyZmq or BlockNotifyWebsocket.
+	// The Indexer only polls the node if it is empty.
+	blockNotify string
+	// zmqBlockHost is the address of the bitcoind ZMQ block publisher, discovered from the node if empty.
+	zmqBlockHost string
+	// chainUrl, chainUser and chainPassword are used to open the btcd websocket connection.
+	chainUrl      string
+	chainUser     string
+	chainPassword string
+
+	// maxSavepoint is the number of savepoints kept for reorg recovery.
+	maxSavepoint uint32
+	// savepointInterval is the number of blocks between savepoints.
+	savepointInterval uint32
+	// chainTipDistance is the distance to the chain tip within which savepoints are created.
+	chainTipDistance uint32
+	// reorgReindexHeight is the height the index is re-indexed from after an unrecoverable reorg, 0 or -1 to stop instead.
+	reorgReindexHeight int64
 }
 
 // Option is a function type that takes a pointer to an Options struct.
@@ -83,9 +102,57 @@ func WithIndexSpendSats(indexSpendSats string) func(*Options) {
 	}
 }
 
-func WithTidbSessionMemLimit(tidbSessionMemLimit int) func(*Options) {
+// WithBlockNotify is a function that returns an Option.
+// This Option sets the block notification source the Indexer subscribes to.
+func WithBlockNotify(blockNotify string) func(*Options) {
+	return func(options *Options) {
+		options.blockNotify = blockNotify
+	}
+}
+
+// WithZmqBlockHost is a function that returns an Option.
+// This Option sets the address of the bitcoind ZMQ block publisher.
+func WithZmqBlockHost(zmqBlockHost string) func(*Options) {
+	return func(options *Options) {
+		options.zmqBlockHost = zmqBlockHost
+	}
+}
+
+// WithChain is a function that returns an Option.
+// This Option sets the node URL and credentials used for the btcd websocket connection.
+func WithChain(url, user, password string) func(*Options) {
+	return func(options *Options) {
+		options.chainUrl = url
+		options.chainUser = user
+		options.chainPassword = password
+	}
+}
+
+// WithReorgProtection is a function that returns an Option.
+// This Option sets the number of savepoints kept, the number of blocks between them
+// and the distance to the chain tip within which they are created.
+// Reorgs up to (maxSavepoint-1)*savepointInterval blocks deep can be rolled back. Zero values keep the defaults.
+func WithReorgProtection(maxSavepoint, savepointInterval, chainTipDistance uint32) func(*Options) {
+	return func(options *Options) {
+		if maxSavepoint > 0 {
+			options.maxSavepoint = maxSavepoint
+		}
+		if savepointInterval > 0 {
+			options.savepointInterval = savepointInterval
+		}
+		if chainTipDistance > 0 {
+			options.chainTipDistance = chainTipDistance
+		}
+	}
+}
+
+// WithReorgReindexHeight is a function that returns an Option.
+// This Option makes the Indexer roll the index back and re-index it from the given height after an unrecoverable reorg,
+// instead of stopping. The block below the height must still be on the chain of the node, and the index must be
+// rebuildable at it, see rollbackTo. A height of 0 or a negative height disables it.
+func WithReorgReindexHeight(height int64) func(*Options) {
 	return func(options *Options) {
-		options.tidbSessionMemLimit = tidbSessionMemLimit
+		options.reorgReindexHeight = height
 	}
 }
 
@@ -111,6 +178,27 @@ type Indexer struct {
 	indexSats bool
 	// indexSpentSats is a boolean that indicates whether to index spent satoshis or not.
 	indexSpentSats bool
+	// blockNotify wakes up the Indexer when a new block is announced.
+	blockNotify chan struct{}
+	// pollInterval is the interval the Indexer polls the node at.
+	pollInterval time.Duration
+	// zmqEvents is the bitcoind ZMQ block subscription.
+	zmqEvents chain.BitcoindEvents
+	// wsCli is the btcd websocket client used for block notifications.
+	wsCli *rpcclient.Client
+	// quit is closed when the Indexer is stopped.
+	quit chan struct{}
+	// done is closed when the indexing loop has exited.
+	done     chan struct{}
+	started  atomic.Bool
+	stopOnce sync.Once
+	// paused makes the Indexer hold after the block being indexed is committed.
+	paused atomic.Bool
+	// stopHeight is the height the Indexer holds at once indexed, -1 if not set.
+	stopHeight atomic.Int64
+	// reorgSubs are the subscribers of reorg events.
+	reorgSubs   map[chan *ReorgEvent]struct{}
+	reorgSubsMu sync.Mutex
 }
 
 // NewIndexer is a function that returns a pointer to a new Indexer instance.
@@ -118,49 +206,129 @@ type Indexer struct {
 // which are used to set the configuration options for the Indexer.
 func NewIndexer(opts ...Option) *Indexer {
 	idx := &Indexer{
-		opts: &Options{},
+		opts: &Options{
+			maxSavepoint:       defaultMaxSavepoint,
+			savepointInterval:  defaultSavepointInterval,
+			chainTipDistance:   defaultChainTipDistance,
+			reorgReindexHeight: -1,
+		},
 	}
 	for _, v := range opts {
 		v(idx.opts)
 	}
 	idx.valueCache = NewValueCache()
 	idx.rangeCache = NewRangeCaches()
+	idx.blockNotify = make(chan struct{}, 1)
+	idx.pollInterval = pollInterval
+	idx.quit = make(chan struct{})
+	idx.done = make(chan struct{})
+	idx.stopHeight.Store(-1)
+	idx.reorgSubs = make(map[chan *ReorgEvent]struct{})
 	return idx
 }
 
 // Start is a method that starts the Indexer.
+// New blocks are picked up on block notifications if configured, otherwise by polling the node.
 func (idx *Indexer) Start() {
+	idx.startBlockNotify()
+	idx.started.Store(true)
 	go func() {
+		defer close(idx.done)
 		for {
 			select {
-			case <-signal.InterruptChannel:
+			case <-idx.quit:
+				return
 			default:
-				err := idx.UpdateIndex()
-				if errors.Is(err, signal.ErrInterrupted) {
+			}
+			if idx.paused.Load() {
+				idx.waitForBlock()
+				continue
+			}
+			err := idx.UpdateIndex()
+			if errors.Is(err, signal.ErrInterrupted) {
+				return
+			}
+			if errors.Is(err, ErrDetectReorg) {
+				log.Srv.Error("UpdateIndex", err)
+				if idx.opts.reorgReindexHeight <= 0 {
+					idx.publishReorg(&ReorgEvent{Height: idx.height})
 					return
 				}
-				if errors.Is(err, ErrDetectReorg) {
-					log.Srv.Error("UpdateIndex", err)
+				if err := idx.reindex(); err != nil {
+					log.Srv.Error("reindex", err)
+					idx.publishReorg(&ReorgEvent{Height: idx.height})
 					return
 				}
-				var recoverable *ErrRecoverable
-				if errors.As(err, &recoverable) {
-					if err := handleReorg(idx, recoverable.Height, recoverable.Depth); err != nil {
-						log.Srv.Error("handleReorg", err)
-						time.Sleep(time.Second * 5)
-					}
-					continue
-				}
-				if err != nil {
-					log.Srv.Error("UpdateIndex", err)
+				continue
+			}
+			var recoverable *ErrRecoverable
+			if errors.As(err, &recoverable) {
+				if err := handleReorg(idx, recoverable.Height, recoverable.Depth); err != nil {
+					log.Srv.Error("handleReorg", err)
+					time.Sleep(time.Second * 5)
 				}
-				time.Sleep(time.Second * 5)
+				continue
+			}
+			if err != nil {
+				log.Srv.Error("UpdateIndex", err)
 			}
+			idx.waitForBlock()
 		}
 	}()
 }
 
+// Stop is a method that stops the Indexer gracefully.
+// The block being indexed is finished and committed together with the value and sat range caches before it returns.
 func (idx *Indexer) Stop() {
+	idx.stopOnce.Do(func() {
+		log.Srv.Info("stopping indexer")
+		close(idx.quit)
+		idx.stopBlockNotify()
+		if idx.started.Load() {
+			<-idx.done
+		}
+		log.Srv.Info("indexer stopped")
+	})
+}
+
+// Pause is a method that pauses the Indexer after the block being indexed is committed.
+func (idx *Indexer) Pause() {
+	idx.paused.Store(true)
+}
+
+// Resume is a method that resumes a paused Indexer and clears the stop height.
+func (idx *Indexer) Resume() {
+	idx.paused.Store(false)
+	idx.stopHeight.Store(-1)
+	idx.notifyBlock()
+}
+
+// StopAt is a method that makes the Indexer hold once the block at the given height is indexed and committed,
+// so the database can be snapshot at that exact height. Resume clears it.
+func (idx *Indexer) StopAt(height uint32) {
+	idx.stopHeight.Store(int64(height))
+	idx.notifyBlock()
+}
+
+// IndexerStatus is the control state of the Indexer.
+type IndexerStatus struct {
+	Paused     bool  `json:"paused"`
+	Stopping   bool  `json:"stopping"`
+	StopHeight int64 `json:"stop_height"` // -1 if no stop height is set
+}
+
+// Status is a method that returns the control state of the Indexer.
+func (idx *Indexer) Status() *IndexerStatus {
+	status := &IndexerStatus{
+		Paused:     idx.paused.Load(),
+		StopHeight: idx.stopHeight.Load(),
+	}
+	select {
+	case <-idx.quit:
+		status.Stopping = true
+	default:
+	}
+	return status
 }
 
 // DB is a method that returns a pointer to the dao.DB instance associated with the Indexer.
@@ -170,15 +338,7 @@ func (idx *Indexer) DB() *dao.DB {
 
 // Begin is a method that starts a new transaction and returns a pointer to the dao.DB instance associated with the transaction.
 func (idx *Indexer) Begin() *dao.DB {
-	session := idx.opts.db.DB.Begin()
-	if idx.opts.db.EmbedDB() {
-		limit := 1
-		if idx.opts.tidbSessionMemLimit > limit {
-			limit = idx.opts.tidbSessionMemLimit
-		}
-		session.Exec(fmt.Sprintf("SET tidb_mem_quota_query = %d << 30;", limit))
-	}
-	return &dao.DB{DB: session}
+	return idx.opts.db.BeginTransaction()
 }
 
 // RpcClient is a method that returns a pointer to the rpcclient.Client instance associated with the Indexer.
@@ -193,12 +353,9 @@ func (idx *Indexer) BatchRpcClient() *rpcclient.Client {
 	return idx.opts.batchCli
 }
 
-// UpdateIndex is a method that updates the index of the blockchain.
-// It fetches blocks from the blockchain, starting from the current height of the indexer, and indexes them.
-// If the indexer is configured to index satoshis, it flushes the satoshi range cache to the database.
-// It also updates various statistics related to the indexing process.
-// The method returns an error if there is any issue during the indexing process.
-func (idx *Indexer) UpdateIndex() error {
+// loadSettings is a method that stores the sat index settings of the Indexer options in the index
+// and loads the settings of the index.
+func (idx *Indexer) loadSettings() error {
 	if idx.opts.indexSats != "" || idx.opts.indexSpentSats != "" {
 		if err := idx.DB().Transaction(func(tx *dao.DB) error {
 			indexSats := gconv.Uint64(gconv.Bool(idx.opts.indexSats) || gconv.Bool(idx.opts.indexSpentSats))
@@ -227,8 +384,21 @@ func (idx *Indexer) UpdateIndex() error {
 		return err
 	}
 	idx.indexSpentSats = indexSpentSats > 0
+	return nil
+}
+
+// UpdateIndex is a method that updates the index of the blockchain.
+// It fetches blocks from the blockchain, starting from the current height of the indexer, and indexes them.
+// If the indexer is configured to index satoshis, it flushes the satoshi range cache to the database.
+// It also updates various statistics related to the indexing process.
+// The method returns an error if there is any issue during the indexing process.
+func (idx *Indexer) UpdateIndex() error {
+	if err := idx.loadSettings(); err != nil {
+		return err
+	}
 
 	// Get the current block count from the database.
+	var err error
 	idx.height, err = idx.DB().BlockCount()
 	if err != nil {
 		return err
@@ -238,6 +408,9 @@ func (idx *Indexer) UpdateIndex() error {
 	if err != nil {
 		return err
 	}
+	if stopHeight := idx.stopHeight.Load(); stopHeight >= 0 && endHeight > stopHeight {
+		endHeight = stopHeight
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	blockCh, errCh := idx.fetchBlockFrom(ctx, uint32(endHeight))
@@ -263,7 +436,7 @@ func (idx *Indexer) UpdateIndex() error {
 
 	for {
 		select {
-		case <-signal.InterruptChannel:
+		case <-idx.quit:
 			goto END
 		case block, ok := <-blockCh:
 			if !ok {
@@ -276,6 +449,11 @@ func (idx *Indexer) UpdateIndex() error {
 			}
 			unCommit++
 
+			// Hold after the block if the Indexer is paused.
+			if idx.paused.Load() {
+				goto END
+			}
+
 			needCommit := idx.needCommit(startTime, unCommit)
 			if needCommit {
 				unCommit = 0
@@ -299,6 +477,11 @@ func (idx *Indexer) UpdateIndex() error {
 				}
 			}
 		case err = <-errCh:
+			// Fetching stops when the Indexer is stopped, commit the blocks indexed so far.
+			if errors.Is(err, signal.ErrInterrupted) {
+				err = nil
+				goto END
+			}
 			return err
 		}
 	}
@@ -308,6 +491,8 @@ END:
 		if err = idx.commit(wtx); err != nil {
 			return err
 		}
+	} else {
+		wtx.Rollback()
 	}
 	return nil
 }
@@ -316,7 +501,7 @@ END:
 // It updates various statistics related to the indexing process.
 // The method returns an error if there is any issue during the indexing process.
 func (idx *Indexer) indexBlock(
-	wtx *dao.DB,
+	wtx dao.Store,
 	block *wire.MsgBlock,
 ) error {
 
@@ -393,46 +578,41 @@ func (idx *Indexer) indexBlock(
 			inputSatRanges := make(tables.SatRanges, 0)
 
 			for _, input := range tx.TxIn {
-				select {
-				case <-signal.InterruptChannel:
-					return nil
-				default:
-					outpoint := input.PreviousOutPoint.String()
-
-					var ok bool
-					var satRanges *bytes.Buffer
+				outpoint := input.PreviousOutPoint.String()
+
+				var ok bool
+				var satRanges *bytes.Buffer
+				if idx.indexSpentSats {
+					satRanges, ok = idx.rangeCache.Read(outpoint)
+				} else {
+					satRanges, ok = idx.rangeCache.Delete(outpoint)
+				}
+				if ok {
+					idx.outputsCached++
+				} else {
+					var outpointSatRanges tables.OutpointSatRange
 					if idx.indexSpentSats {
-						satRanges, ok = idx.rangeCache.Read(outpoint)
-					} else {
-						satRanges, ok = idx.rangeCache.Delete(outpoint)
-					}
-					if ok {
-						idx.outputsCached++
-					} else {
-						var outpointSatRanges tables.OutpointSatRange
-						if idx.indexSpentSats {
-							outpointSatRanges, err = wtx.OutpointToSatRanges(outpoint)
-							if err != nil {
-								return err
-							}
-						} else {
-							outpointSatRanges, err = wtx.DelSatRangesByOutpoint(idx.height, outpoint)
-							if err != nil {
-								return err
-							}
+						outpointSatRanges, err = wtx.OutpointToSatRanges(outpoint)
+						if err != nil {
+							return err
 						}
-						if outpointSatRanges.Id == 0 {
-							return fmt.Errorf("could not find outpoint %s in index", outpoint)
+					} else {
+						outpointSatRanges, err = wtx.DelSatRangesByOutpoint(idx.height, outpoint)
+						if err != nil {
+							return err
 						}
-						satRanges = bytes.NewBuffer(outpointSatRanges.SatRange)
 					}
-
-					satRangesEntry, err := tables.NewSatRanges(satRanges.Bytes())
-					if err != nil {
-						return err
+					if outpointSatRanges.Id == 0 {
+						return fmt.Errorf("could not find outpoint %s in index", outpoint)
 					}
-					inputSatRanges = append(inputSatRanges, satRangesEntry...)
+					satRanges = bytes.NewBuffer(outpointSatRanges.SatRange)
+				}
+
+				satRangesEntry, err := tables.NewSatRanges(satRanges.Bytes())
+				if err != nil {
+					return err
 				}
+				inputSatRanges = append(inputSatRanges, satRangesEntry...)
 			}
 
 			if err := idx.indexTransactionSats(
@@ -511,10 +691,14 @@ func (idx *Indexer) indexBlock(
 	if err := block.Header.Serialize(buf); err != nil {
 		return err
 	}
+	if !idx.indexSats {
+		lostSats = *inscriptionUpdater.lostSats
+	}
 	blockInfo := &tables.BlockInfo{
 		Height:    idx.height,
 		Header:    buf.Bytes(),
 		Timestamp: block.Header.Timestamp.Unix(),
+		LostSats:  &lostSats,
 	}
 	if *inscriptionUpdater.nextSequenceNumber > sequenceNumber {
 		blockInfo.SequenceNum = *inscriptionUpdater.nextSequenceNumber
@@ -523,14 +707,8 @@ func (idx *Indexer) indexBlock(
 		return err
 	}
 
-	if idx.indexSats {
-		if err := wtx.SetStatistic(idx.height, tables.StatisticLostSats, lostSats); err != nil {
-			return err
-		}
-	} else {
-		if err := wtx.SetStatistic(idx.height, tables.StatisticLostSats, *inscriptionUpdater.lostSats); err != nil {
-			return err
-		}
+	if err := wtx.SetStatistic(idx.height, tables.StatisticLostSats, lostSats); err != nil {
+		return err
 	}
 	if err := wtx.SetStatistic(idx.height, tables.StatisticCursedInscriptions, *inscriptionUpdater.cursedInscriptionCount); err != nil {
 		return err
@@ -549,7 +727,7 @@ func (idx *Indexer) indexBlock(
 }
 
 // indexTransactionSats is a method that indexes the satoshis in a transaction.
-// It takes as arguments a pointer to a dao.DB instance (wtx), a pointer to a wire.MsgTx instance (tx),
+// It takes as arguments a dao.Store (wtx), a pointer to a wire.MsgTx instance (tx),
 // a slice of pointers to tables.OutpointSatRange instances (inputSatRanges), a pointer to an uint64 (satRangesWritten),
 // a pointer to an uint64 (outputsInBlock), a pointer to an InscriptionUpdater instance (inscriptionUpdater),
 // and a boolean (indexInscriptions).
@@ -565,7 +743,7 @@ func (idx *Indexer) indexBlock(
 // It then adds the slice of satoshis to the range cache, and increments the number of outputs inserted since the last flush.
 // Finally, it sets the list of input satoshi ranges to the remaining input satoshi ranges, and returns the list and any error that occurred during the process.
 func (idx *Indexer) indexTransactionSats(
-	wtx *dao.DB,
+	wtx dao.Store,
 	tx *wire.MsgTx,
 	inputSatRanges *tables.SatRanges,
 	satRangesWritten *uint64,
@@ -580,63 +758,59 @@ func (idx *Indexer) indexTransactionSats(
 	}
 
 	for i, txOut := range tx.TxOut {
-		select {
-		case <-signal.InterruptChannel:
-		default:
-			outpoint := wire.OutPoint{
-				Hash:  tx.TxHash(),
-				Index: uint32(i),
-			}.String()
+		outpoint := wire.OutPoint{
+			Hash:  tx.TxHash(),
+			Index: uint32(i),
+		}.String()
 
-			sats := bytes.NewBufferString("")
-			remaining := txOut.Value
+		sats := bytes.NewBufferString("")
+		remaining := txOut.Value
 
-			for remaining > 0 {
-				if len(*inputSatRanges) == 0 {
-					return errors.New("insufficient inputs for transaction outputs")
+		for remaining > 0 {
+			if len(*inputSatRanges) == 0 {
+				return errors.New("insufficient inputs for transaction outputs")
+			}
+			firstRange := (*inputSatRanges)[0]
+			*inputSatRanges = (*inputSatRanges)[1:]
+			startSat := Sat(firstRange.Start)
+
+			if !startSat.Common() {
+				offset := txOut.Value - remaining
+				if offset < 0 {
+					return errors.New("negative offset")
 				}
-				firstRange := (*inputSatRanges)[0]
-				*inputSatRanges = (*inputSatRanges)[1:]
-				startSat := Sat(firstRange.Start)
-
-				if !startSat.Common() {
-					offset := txOut.Value - remaining
-					if offset < 0 {
-						return errors.New("negative offset")
-					}
-					if err := wtx.SatToSatPoint(idx.height, &tables.SatSatPoint{
-						Sat:      firstRange.Start,
-						Outpoint: outpoint,
-						Offset:   uint64(offset),
-					}); err != nil {
-						return err
-					}
+				if err := wtx.SatToSatPoint(idx.height, &tables.SatSatPoint{
+					Sat:      firstRange.Start,
+					Outpoint: outpoint,
+					Offset:   uint64(offset),
+				}); err != nil {
+					return err
 				}
+			}
 
-				count := int64(firstRange.End) - int64(firstRange.Start)
-				assigned := firstRange
-
-				if count > remaining {
-					idx.satRangesSinceFlush++
-					middle := firstRange.Start + uint64(remaining)
-					*inputSatRanges = append([]*tables.SatRange{
-						{
-							Start: middle,
-							End:   firstRange.End,
-						},
-					}, *inputSatRanges...)
-					assigned.End = middle
-				}
-				sats.Write(assigned.Store())
-				remaining -= int64(assigned.End) - int64(assigned.Start)
-				*satRangesWritten++
+			count := int64(firstRange.End) - int64(firstRange.Start)
+			assigned := firstRange
+
+			if count > remaining {
+				idx.satRangesSinceFlush++
+				middle := firstRange.Start + uint64(remaining)
+				*inputSatRanges = append([]*tables.SatRange{
+					{
+						Start: middle,
+						End:   firstRange.End,
+					},
+				}, *inputSatRanges...)
+				assigned.End = middle
 			}
+			sats.Write(assigned.Store())
+			remaining -= int64(assigned.End) - int64(assigned.Start)
+			*satRangesWritten++
+		}
 
-			*outputsTraversed++
+		*outputsTraversed++
 
-			idx.rangeCache.Write(outpoint, sats.Bytes())
-			idx.outputsInsertedSinceFlush++
-		}
+		idx.rangeCache.Write(outpoint, sats.Bytes())
+		idx.outputsInsertedSinceFlush++
 	}
 	return nil
 }
@@ -654,6 +828,23 @@ func (idx *Indexer) needCommit(
 
 // detectReorg is a method that detects if there is a reorganization in the blockchain.
 func (idx *Indexer) commit(wtx *dao.DB) (err error) {
+	if err = idx.flush(wtx); err != nil {
+		return err
+	}
+	// update undo log save points
+	if err = updateSavePoints(idx, wtx, idx.height-1); err != nil {
+		return err
+	}
+	wtx.Commit()
+
+	idx.outputsInsertedSinceFlush = 0
+	idx.valueCache = NewValueCache()
+	idx.rangeCache = NewRangeCaches()
+	return nil
+}
+
+// flush writes the value and sat range caches and the statistics of the blocks indexed in wtx.
+func (idx *Indexer) flush(wtx *dao.DB) (err error) {
 	height := idx.height - 1
 	log.Srv.Infof(
 		"Committing at block %d, %d outputs traversed, %d in map, %d cached",
@@ -698,16 +889,6 @@ func (idx *Indexer) commit(wtx *dao.DB) (err error) {
 	if err = wtx.IncrementStatistic(height, tables.StatisticCommits, 1); err != nil {
 		return err
 	}
-
-	// update undo log save points
-	if err := updateSavePoints(idx, wtx, height); err != nil {
-		return err
-	}
-	wtx.Commit()
-
-	idx.outputsInsertedSinceFlush = 0
-	idx.valueCache = NewValueCache()
-	idx.rangeCache = NewRangeCaches()
 	return nil
 }
 
@@ -805,7 +986,7 @@ func (idx *Indexer) getBlockWithRetries(height uint32) (*wire.MsgBlock, error) {
 	errs := -1
 	for {
 		select {
-		case <-signal.InterruptChannel:
+		case <-idx.quit:
 			return nil, signal.ErrInterrupted
 		default:
 			errs++
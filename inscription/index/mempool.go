@@ -0,0 +1,247 @@
+// Package index provides the implementation of the mempool watcher.
+package index
+
+import (
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/inscription/log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// mempoolSyncInterval is the interval the mempool watcher syncs with the node at.
+	mempoolSyncInterval = time.Second * 10
+	// mempoolFetchBatch is the number of transactions requested from the node at once.
+	mempoolFetchBatch = 100
+	// mempoolOutpointBatch is the number of spent outpoints looked up in the index at once.
+	mempoolOutpointBatch = 1000
+	// mempoolMaxTxs is the number of transactions tracked at most, the transactions
+	// beyond it are fetched by the next syncs once others left the mempool.
+	mempoolMaxTxs = 300_000
+)
+
+// PendingInscription is an inscription revealed by an unconfirmed transaction.
+type PendingInscription struct {
+	InscriptionId string `json:"inscription_id"`
+	ContentType   string `json:"content_type"`
+	ContentLength int    `json:"content_length"`
+	Metaprotocol  string `json:"metaprotocol"`
+	Owner         string `json:"address"`
+	FirstSeen     int64  `json:"first_seen"`
+}
+
+// PendingTransfer is an inscription spent by an unconfirmed transaction.
+type PendingTransfer struct {
+	InscriptionId string `json:"inscription_id"`
+	TxId          string `json:"txid"`
+	OldSatPoint   string `json:"old_satpoint"`
+	FirstSeen     int64  `json:"first_seen"`
+}
+
+// mempoolTx holds the pending inscriptions and transfers of an unconfirmed transaction.
+type mempoolTx struct {
+	inscriptions []*PendingInscription
+	transfers    []*PendingTransfer
+}
+
+// Mempool watches the mempool of the node for pending inscriptions and transfers.
+// Transactions are evicted once they leave the mempool, either mined or dropped.
+type Mempool struct {
+	db  dao.Store
+	cli *rpcclient.Client
+
+	mu  sync.RWMutex
+	txs map[chainhash.Hash]*mempoolTx
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMempool is a function that returns a pointer to a new Mempool instance.
+func NewMempool(db dao.Store, cli *rpcclient.Client) *Mempool {
+	return &Mempool{
+		db:   db,
+		cli:  cli,
+		txs:  make(map[chainhash.Hash]*mempoolTx),
+		quit: make(chan struct{}),
+	}
+}
+
+// Start is a method that syncs the Mempool with the node until it is stopped.
+func (m *Mempool) Start() {
+	go func() {
+		for {
+			if err := m.Sync(); err != nil {
+				log.Srv.Warn("Mempool.Sync", err)
+			}
+			select {
+			case <-m.quit:
+				return
+			case <-time.After(mempoolSyncInterval):
+			}
+		}
+	}()
+}
+
+// Stop is a method that stops syncing the Mempool.
+func (m *Mempool) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.quit)
+	})
+}
+
+// Sync is a method that fetches the transactions that entered the mempool since the last sync
+// and evicts the transactions that left it.
+func (m *Mempool) Sync() error {
+	hashes, err := m.cli.GetRawMempool()
+	if err != nil {
+		return err
+	}
+	return m.sync(hashes, m.fetchTxs)
+}
+
+// sync is a method that evicts the transactions not in the mempool given by its hashes,
+// and fetches and parses the transactions not tracked yet in batches, up to mempoolMaxTxs.
+// fetch returns the transactions of the hashes, nil for the transactions that left the mempool.
+func (m *Mempool) sync(hashes []*chainhash.Hash, fetch func(hashes []*chainhash.Hash) []*wire.MsgTx) error {
+	current := make(map[chainhash.Hash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		current[*hash] = struct{}{}
+	}
+
+	m.mu.Lock()
+	for hash := range m.txs {
+		if _, ok := current[hash]; !ok {
+			delete(m.txs, hash)
+		}
+	}
+	added := make([]*chainhash.Hash, 0)
+	for _, hash := range hashes {
+		if _, ok := m.txs[*hash]; !ok && len(m.txs)+len(added) < mempoolMaxTxs {
+			added = append(added, hash)
+		}
+	}
+	m.mu.Unlock()
+
+	for start := 0; start < len(added); start += mempoolFetchBatch {
+		end := min(start+mempoolFetchBatch, len(added))
+		txs := fetch(added[start:end])
+		held, err := m.heldInscriptions(txs)
+		if err != nil {
+			return err
+		}
+		m.mu.Lock()
+		for i, tx := range txs {
+			if tx != nil {
+				m.txs[*added[start+i]] = parseTx(tx, held)
+			}
+		}
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// fetchTxs is a method that requests transactions from the node at once.
+// The transactions that left the mempool since getrawmempool are nil.
+func (m *Mempool) fetchTxs(hashes []*chainhash.Hash) []*wire.MsgTx {
+	futures := make([]rpcclient.FutureGetRawTransactionResult, 0, len(hashes))
+	for _, hash := range hashes {
+		futures = append(futures, m.cli.GetRawTransactionAsync(hash))
+	}
+	txs := make([]*wire.MsgTx, len(hashes))
+	for i, future := range futures {
+		if tx, err := future.Receive(); err == nil {
+			txs[i] = tx.MsgTx()
+		}
+	}
+	return txs
+}
+
+// heldInscriptions is a method that looks up the inscriptions held by the outpoints
+// spent by transactions, in batches of mempoolOutpointBatch outpoints, by outpoint.
+func (m *Mempool) heldInscriptions(txs []*wire.MsgTx) (map[string][]*dao.OutpointInscription, error) {
+	outpoints := make([]string, 0)
+	for _, tx := range txs {
+		if tx == nil {
+			continue
+		}
+		for _, input := range tx.TxIn {
+			outpoints = append(outpoints, tables.FormatOutpoint(input.PreviousOutPoint.Hash.String(), input.PreviousOutPoint.Index))
+		}
+	}
+	held := make(map[string][]*dao.OutpointInscription)
+	for start := 0; start < len(outpoints); start += mempoolOutpointBatch {
+		end := min(start+mempoolOutpointBatch, len(outpoints))
+		list, err := m.db.InscriptionsByOutpoints(outpoints[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range list {
+			held[v.Outpoint] = append(held[v.Outpoint], v)
+		}
+	}
+	return held, nil
+}
+
+// parseTx is a function that extracts the inscriptions revealed by a transaction and
+// the inscriptions spent by it, given the inscriptions held by the outpoints it spends.
+func parseTx(tx *wire.MsgTx, held map[string][]*dao.OutpointInscription) *mempoolTx {
+	now := time.Now().Unix()
+	txid := tx.TxHash().String()
+	entry := &mempoolTx{}
+
+	for i, envelope := range ParsedEnvelopFromTransaction(tx) {
+		entry.inscriptions = append(entry.inscriptions, &PendingInscription{
+			InscriptionId: tables.NewInscriptionId(txid, uint32(i)).String(),
+			ContentType:   string(envelope.payload.ContentType),
+			ContentLength: len(envelope.payload.Body),
+			Metaprotocol:  envelope.payload.Metaprotocol,
+			Owner:         envelope.owner,
+			FirstSeen:     now,
+		})
+	}
+
+	for _, input := range tx.TxIn {
+		outpoint := tables.FormatOutpoint(input.PreviousOutPoint.Hash.String(), input.PreviousOutPoint.Index)
+		for _, v := range held[outpoint] {
+			satPoint := &tables.SatPointToSequenceNum{Outpoint: v.Outpoint, Offset: v.SatOffset}
+			entry.transfers = append(entry.transfers, &PendingTransfer{
+				InscriptionId: v.InscriptionId.String(),
+				TxId:          txid,
+				OldSatPoint:   satPoint.String(),
+				FirstSeen:     now,
+			})
+		}
+	}
+	return entry
+}
+
+// Pending is a method that returns the pending inscriptions and transfers, oldest first.
+func (m *Mempool) Pending() (inscriptions []*PendingInscription, transfers []*PendingTransfer) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	inscriptions = make([]*PendingInscription, 0)
+	transfers = make([]*PendingTransfer, 0)
+	for _, v := range m.txs {
+		inscriptions = append(inscriptions, v.inscriptions...)
+		transfers = append(transfers, v.transfers...)
+	}
+	sort.SliceStable(inscriptions, func(i, j int) bool {
+		if inscriptions[i].FirstSeen != inscriptions[j].FirstSeen {
+			return inscriptions[i].FirstSeen < inscriptions[j].FirstSeen
+		}
+		return inscriptions[i].InscriptionId < inscriptions[j].InscriptionId
+	})
+	sort.SliceStable(transfers, func(i, j int) bool {
+		if transfers[i].FirstSeen != transfers[j].FirstSeen {
+			return transfers[i].FirstSeen < transfers[j].FirstSeen
+		}
+		return transfers[i].InscriptionId < transfers[j].InscriptionId
+	})
+	return
+}
@@ -0,0 +1,110 @@
+package index
+
+import (
+	"fmt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"gotest.tools/assert"
+	"testing"
+)
+
+// newMempoolTestTx returns a transaction spending an outpoint, revealing an inscription if reveal is set.
+func newMempoolTestTx(t *testing.T, spent wire.OutPoint, reveal bool) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	txIn := wire.NewTxIn(&spent, nil, nil)
+	if reveal {
+		script, err := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_FALSE).
+			AddOp(txscript.OP_IF).
+			AddData([]byte(constants.ProtocolId)).
+			AddData(TagContentType.Bytes()).
+			AddData([]byte("text/plain")).
+			AddOp(txscript.OP_0).
+			AddData([]byte("cins")).
+			AddOp(txscript.OP_ENDIF).
+			Script()
+		assert.NilError(t, err)
+		txIn.Witness = wire.TxWitness{make([]byte, 64), script, make([]byte, 33)}
+	}
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(546, nil))
+	return tx
+}
+
+func TestMempoolParseTx(t *testing.T) {
+	spent := wire.OutPoint{Hash: chainhash.Hash{1}, Index: 1}
+	outpoint := tables.FormatOutpoint(spent.Hash.String(), spent.Index)
+	tx := newMempoolTestTx(t, spent, true)
+	entry := parseTx(tx, map[string][]*dao.OutpointInscription{
+		outpoint: {{
+			InscriptionId: tables.InscriptionId{TxId: fmt.Sprintf("%064d", 1)},
+			Outpoint:      outpoint,
+			SatOffset:     10,
+		}},
+	})
+
+	assert.Equal(t, len(entry.inscriptions), 1)
+	assert.Equal(t, entry.inscriptions[0].InscriptionId, tables.NewInscriptionId(tx.TxHash().String(), 0).String())
+	assert.Equal(t, entry.inscriptions[0].ContentType, "text/plain")
+	assert.Equal(t, entry.inscriptions[0].ContentLength, 4)
+	assert.Equal(t, len(entry.transfers), 1)
+	assert.Equal(t, entry.transfers[0].InscriptionId, tables.NewInscriptionId(fmt.Sprintf("%064d", 1), 0).String())
+	assert.Equal(t, entry.transfers[0].TxId, tx.TxHash().String())
+	assert.Equal(t, entry.transfers[0].OldSatPoint, outpoint+":10")
+}
+
+func TestMempoolSync(t *testing.T) {
+	db := newTestDB(t)
+	spent := wire.OutPoint{Hash: chainhash.Hash{1}, Index: 0}
+	assert.NilError(t, db.CreateInscription(&tables.Inscriptions{
+		InscriptionId: tables.InscriptionId{TxId: fmt.Sprintf("%064d", 1)},
+		SequenceNum:   1,
+	}))
+	assert.NilError(t, db.SetSatPointToSequenceNum(1, &tables.SatPointToSequenceNum{
+		Outpoint:    tables.FormatOutpoint(spent.Hash.String(), spent.Index),
+		SequenceNum: 1,
+	}))
+
+	transfer := newMempoolTestTx(t, spent, false)
+	reveal := newMempoolTestTx(t, wire.OutPoint{Hash: chainhash.Hash{2}}, true)
+	other := newMempoolTestTx(t, wire.OutPoint{Hash: chainhash.Hash{3}}, false)
+	txs := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, tx := range []*wire.MsgTx{transfer, reveal, other} {
+		txs[tx.TxHash()] = tx
+	}
+	fetched := 0
+	fetch := func(hashes []*chainhash.Hash) []*wire.MsgTx {
+		res := make([]*wire.MsgTx, 0, len(hashes))
+		for _, hash := range hashes {
+			res = append(res, txs[*hash])
+			fetched++
+		}
+		return res
+	}
+	hash := func(tx *wire.MsgTx) *chainhash.Hash {
+		h := tx.TxHash()
+		return &h
+	}
+	left := &chainhash.Hash{4}
+
+	m := NewMempool(db, nil)
+	assert.NilError(t, m.sync([]*chainhash.Hash{hash(transfer), hash(reveal), left}, fetch))
+	inscriptions, transfers := m.Pending()
+	assert.Equal(t, len(inscriptions), 1)
+	assert.Equal(t, len(transfers), 1)
+	assert.Equal(t, transfers[0].TxId, transfer.TxHash().String())
+	// a transaction that left the mempool before it was fetched is not tracked
+	assert.Equal(t, len(m.txs), 2)
+
+	// the tracked transactions are not fetched again, the mined ones are evicted
+	assert.NilError(t, m.sync([]*chainhash.Hash{hash(reveal), hash(other)}, fetch))
+	assert.Equal(t, fetched, 4)
+	inscriptions, transfers = m.Pending()
+	assert.Equal(t, len(inscriptions), 1)
+	assert.Equal(t, len(transfers), 0)
+	assert.Equal(t, len(m.txs), 2)
+}
@@ -9,8 +9,11 @@ type Inscription struct {
 	Body            []byte
 	ContentEncoding []byte
 	ContentType     constants.ContentType
+	Delegate        *tables.InscriptionId
 	CInsDescription tables.CInsDescription
 	Metadata        []byte
+	Metaprotocol    string
+	Parents         []*tables.InscriptionId
 	Pointer         []byte
 
 	UnRecognizedEvenField bool
@@ -0,0 +1,166 @@
+// Package index provides the block notification sources of the Indexer.
+package index
+
+import (
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/log"
+	"github.com/inscription-c/cins/pkg/wallet/chain"
+	"net/url"
+	"time"
+)
+
+// Block notification sources supported by the Indexer.
+// Without a source the Indexer only polls the node.
+const (
+	BlockNotifyZmq       = "zmq"       // bitcoind ZMQ rawblock publisher
+	BlockNotifyWebsocket = "websocket" // btcd websocket notifyblocks
+)
+
+const (
+	// pollInterval is the interval the Indexer polls the node at without block notifications.
+	pollInterval = time.Second * 5
+	// notifyPollInterval is the fallback polling interval used when block notifications are enabled.
+	notifyPollInterval = time.Minute
+	// zmqReadDeadline is the read deadline of the ZMQ block subscription.
+	zmqReadDeadline = time.Second * 5
+)
+
+// startBlockNotify subscribes to the block notification source configured for the Indexer.
+// Polling is kept as a fallback, so the Indexer keeps working if the subscription fails.
+func (idx *Indexer) startBlockNotify() {
+	idx.pollInterval = pollInterval
+	var err error
+	switch idx.opts.blockNotify {
+	case "":
+		return
+	case BlockNotifyZmq:
+		err = idx.subscribeZmq()
+	case BlockNotifyWebsocket:
+		err = idx.subscribeWebsocket()
+	default:
+		err = fmt.Errorf("unknown block notify %s", idx.opts.blockNotify)
+	}
+	if err != nil {
+		log.Srv.Warnf("block notifications disabled, polling every %s: %v", idx.pollInterval, err)
+		return
+	}
+	idx.pollInterval = notifyPollInterval
+}
+
+// stopBlockNotify closes the block notification subscription of the Indexer.
+func (idx *Indexer) stopBlockNotify() {
+	if idx.zmqEvents != nil {
+		if err := idx.zmqEvents.Stop(); err != nil {
+			log.Srv.Warnf("unable to stop zmq block notifications: %v", err)
+		}
+	}
+	if idx.wsCli != nil {
+		idx.wsCli.Shutdown()
+	}
+}
+
+// notifyBlock wakes up the Indexer without blocking the notification source.
+func (idx *Indexer) notifyBlock() {
+	select {
+	case idx.blockNotify <- struct{}{}:
+	default:
+	}
+}
+
+// waitForBlock blocks until a new block is announced or the polling interval passes.
+func (idx *Indexer) waitForBlock() {
+	select {
+	case <-idx.blockNotify:
+	case <-time.After(idx.pollInterval):
+	case <-idx.quit:
+	}
+}
+
+// subscribeZmq subscribes to the rawblock publisher of bitcoind.
+// If no publisher address is configured, it is discovered with getzmqnotifications.
+func (idx *Indexer) subscribeZmq() error {
+	addr := idx.opts.zmqBlockHost
+	if addr == "" {
+		notifications, err := idx.opts.cli.GetZmqNotifications()
+		if err != nil {
+			return err
+		}
+		for _, v := range notifications {
+			if v.Address != nil && v.Type == "pubrawblock" {
+				addr = v.Address.String()
+				break
+			}
+		}
+		if addr == "" {
+			return errors.New("bitcoind has no zmqpubrawblock publisher")
+		}
+	}
+
+	events, err := chain.NewBitcoindEventSubscriber(&chain.BitcoindConfig{
+		ZMQConfig: &chain.ZMQConfig{
+			ZMQBlockHost:    addr,
+			ZMQReadDeadline: zmqReadDeadline,
+		},
+	}, idx.opts.cli, idx.opts.batchCli)
+	if err != nil {
+		return err
+	}
+	if err := events.Start(); err != nil {
+		_ = events.Stop()
+		return err
+	}
+	idx.zmqEvents = events
+	log.Srv.Infof("subscribed to bitcoind rawblock notifications on %s", addr)
+
+	go idx.forwardBlocks(events.BlockNotifications())
+	return nil
+}
+
+// forwardBlocks wakes up the Indexer for every block received until the Indexer is stopped.
+func (idx *Indexer) forwardBlocks(blocks <-chan *wire.MsgBlock) {
+	for {
+		select {
+		case <-blocks:
+			idx.notifyBlock()
+		case <-idx.quit:
+			return
+		}
+	}
+}
+
+// subscribeWebsocket subscribes to block notifications of btcd over its websocket endpoint.
+// The RPC client of the Indexer runs in HTTP POST mode, so a separate websocket client is created.
+func (idx *Indexer) subscribeWebsocket() error {
+	chainUrl, err := url.Parse(idx.opts.chainUrl)
+	if err != nil {
+		return err
+	}
+	cli, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:       chainUrl.Host,
+		Endpoint:   "ws",
+		User:       idx.opts.chainUser,
+		Pass:       idx.opts.chainPassword,
+		DisableTLS: chainUrl.Scheme != "https",
+	}, &rpcclient.NotificationHandlers{
+		OnFilteredBlockConnected: func(height int32, header *wire.BlockHeader, txs []*btcutil.Tx) {
+			idx.notifyBlock()
+		},
+		OnFilteredBlockDisconnected: func(height int32, header *wire.BlockHeader) {
+			idx.notifyBlock()
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := cli.NotifyBlocks(); err != nil {
+		cli.Shutdown()
+		return err
+	}
+	idx.wsCli = cli
+	log.Srv.Infof("subscribed to btcd block notifications on %s", chainUrl.Host)
+	return nil
+}
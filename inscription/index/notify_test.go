@@ -0,0 +1,36 @@
+package index
+
+import (
+	"github.com/btcsuite/btcd/wire"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestForwardBlocks(t *testing.T) {
+	idx := &Indexer{
+		blockNotify:  make(chan struct{}, 1),
+		pollInterval: time.Minute,
+		quit:         make(chan struct{}),
+	}
+	blocks := make(chan *wire.MsgBlock)
+	done := make(chan struct{})
+	go func() {
+		idx.forwardBlocks(blocks)
+		close(done)
+	}()
+
+	// A received block wakes up the Indexer, and a second one does not block while the first is pending.
+	blocks <- &wire.MsgBlock{}
+	blocks <- &wire.MsgBlock{}
+	idx.waitForBlock()
+	assert.Equal(t, len(idx.blockNotify), 0)
+
+	// The forwarding stops with the Indexer.
+	close(idx.quit)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("block forwarding did not stop")
+	}
+}
@@ -5,16 +5,18 @@ import (
 	"github.com/inscription-c/cins/constants"
 	"github.com/inscription-c/cins/inscription/index/dao"
 	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/inscription/log"
 	"github.com/inscription-c/cins/pkg/util"
+	"math"
 )
 
 type Protocol struct {
-	wtx   *dao.DB
+	wtx   dao.Store
 	entry *tables.Inscriptions
 }
 
 // NewProtocol is a function that returns a new protocol.
-func NewProtocol(wtx *dao.DB, entry *tables.Inscriptions) *Protocol {
+func NewProtocol(wtx dao.Store, entry *tables.Inscriptions) *Protocol {
 	return &Protocol{
 		wtx:   wtx,
 		entry: entry,
@@ -48,7 +50,7 @@ func (p *Protocol) brc20(brc20 *util.CBRC20) error {
 		//	return nil
 		//}
 
-		if err := p.wtx.SaveProtocol(p.entry.Height, &tables.Protocol{
+		return p.wtx.SaveProtocol(p.entry.Height, &tables.Protocol{
 			InscriptionId: tables.InscriptionId{
 				TxId:   p.entry.TxId,
 				Offset: p.entry.Offset,
@@ -62,54 +64,173 @@ func (p *Protocol) brc20(brc20 *util.CBRC20) error {
 			Max:         gconv.Uint64(brc20.Max),
 			Limit:       gconv.Uint64(brc20.Limit),
 			Decimals:    gconv.Uint32(brc20.Decimals),
-		}); err != nil {
-			return err
-		}
-		//case constants.OperationMint:
-		//	inscriptionId := tables.StringToInscriptionId(brc20c.TkId)
-		//	deploy, err := p.wtx.GetProtocolByInscriptionId(inscriptionId)
-		//	if err != nil {
-		//		return err
-		//	}
-		//	if deploy.Id == 0 {
-		//		log.Log.Warnf("tkid: %s not exists", brc20c.TkId)
-		//		return nil
-		//	}
-		//	if deploy.Protocol != constants.OperationDeploy {
-		//		log.Log.Warnf("tkid: %s is not deploy operation", brc20c.TkId)
-		//		return nil
-		//	}
-		//	if deploy.Limit < gconv.Uint64(brc20c.Amount) {
-		//		log.Log.Warnf("protocol %s, %s, %s amount %s exceeds limit %d", brc20c.Protocol, brc20c.Tick, brc20c.Operation, brc20c.Amount, deploy.Limit)
-		//		return nil
-		//	}
-		//	totalAmount, err := p.wtx.SumProtocolAmount(brc20c.Protocol, brc20c.Tick, brc20c.Operation, brc20c.TkId)
-		//	if err != nil {
-		//		return err
-		//	}
-		//	totalAmount += gconv.Uint64(brc20c.Amount)
-		//	if totalAmount > deploy.Max {
-		//		log.Log.Warnf("protocol %s, %s, %s total amount %d exceeds max %d", brc20c.Protocol, brc20c.Tick, brc20c.Operation, totalAmount, deploy.Max)
-		//		return nil
-		//	}
-		//	mint := &tables.Protocol{
-		//		InscriptionId: tables.InscriptionId{
-		//			TxId:   p.entry.TxId,
-		//			Offset: p.entry.Offset,
-		//		},
-		//		Index:       p.entry.Index,
-		//		SequenceNum: p.entry.SequenceNum,
-		//		Protocol:    constants.ProtocolBRC20C,
-		//		Ticker:      brc20c.Tick,
-		//		Operator:    brc20c.Operation,
-		//		TkID:        inscriptionId.String(),
-		//		Amount:      gconv.Uint64(brc20c.Amount),
-		//		To:          brc20c.To,
-		//		Miner:       p.miner,
-		//	}
-		//	if err := p.wtx.SaveProtocol(mint); err != nil {
-		//		return err
-		//	}
+		})
+	case constants.OperationMint:
+		return p.brc20Mint(brc20)
+	case constants.OperationTransfer:
+		return p.brc20Transfer(brc20)
 	}
 	return nil
 }
+
+// brc20Deploy is a function that returns the deploy that a mint or transfer refers to by tkid.
+// It returns nil if the tkid is not a c-brc-20 deploy of the same ticker.
+func (p *Protocol) brc20Deploy(brc20 *util.CBRC20) (*tables.Protocol, error) {
+	deploy, err := p.wtx.GetProtocolByInscriptionId(tables.StringToInscriptionId(brc20.TkId))
+	if err != nil {
+		return nil, err
+	}
+	if deploy.Id == 0 {
+		log.Srv.Warnf("tkid: %s not exists", brc20.TkId)
+		return nil, nil
+	}
+	if deploy.Protocol != constants.ProtocolCBRC20 || deploy.Operator != constants.OperationDeploy {
+		log.Srv.Warnf("tkid: %s is not deploy operation", brc20.TkId)
+		return nil, nil
+	}
+	if deploy.Ticker != brc20.Tick {
+		log.Srv.Warnf("tkid: %s ticker %s not match %s", brc20.TkId, deploy.Ticker, brc20.Tick)
+		return nil, nil
+	}
+	return &deploy, nil
+}
+
+// brc20Mint is a function that saves a brc20c mint.
+// The mint is bound to its deploy by tkid, mints above the deploy limit are ignored,
+// and the last mint is truncated so that the total minted never exceeds the deploy max.
+// A zero limit on the deploy means no limit, a zero max means a supply of math.MaxInt64,
+// so that the minted total fits the signed 64-bit integer columns of every database.
+// The total minted is kept on the deploy, its update is rolled back with the block of the mint.
+func (p *Protocol) brc20Mint(brc20 *util.CBRC20) error {
+	deploy, err := p.brc20Deploy(brc20)
+	if err != nil || deploy == nil {
+		return err
+	}
+	tkid := deploy.InscriptionId.String()
+
+	amount, ok := mintAmount(deploy, gconv.Uint64(brc20.Amount))
+	if !ok {
+		log.Srv.Warnf("tkid: %s mint amount %s exceeds limit %d or max %d, minted %d",
+			brc20.TkId, brc20.Amount, deploy.Limit, deploy.Max, deploy.Minted)
+		return nil
+	}
+	deploy.Minted += amount
+	if err := p.wtx.SaveProtocol(p.entry.Height, deploy); err != nil {
+		return err
+	}
+
+	if err := p.wtx.SaveProtocol(p.entry.Height, &tables.Protocol{
+		InscriptionId: tables.InscriptionId{
+			TxId:   p.entry.TxId,
+			Offset: p.entry.Offset,
+		},
+		Index:       p.entry.Index,
+		Owner:       p.entry.Owner,
+		SequenceNum: p.entry.SequenceNum,
+		Protocol:    brc20.Protocol,
+		Ticker:      brc20.Tick,
+		Operator:    brc20.Operation,
+		TkId:        tkid,
+		Amount:      amount,
+	}); err != nil {
+		return err
+	}
+	return p.wtx.UpdateBalance(p.entry.Height, tkid, deploy.Ticker, p.entry.Owner, int64(amount), 0)
+}
+
+// mintAmount returns the amount minted by a mint of amount on deploy, truncated to the remaining supply.
+// It returns false if the amount exceeds the limit or the supply is minted out.
+func mintAmount(deploy *tables.Protocol, amount uint64) (uint64, bool) {
+	if deploy.Limit > 0 && amount > deploy.Limit {
+		return 0, false
+	}
+	supply := deploy.Max
+	if supply == 0 || supply > math.MaxInt64 {
+		supply = math.MaxInt64
+	}
+	if deploy.Minted >= supply {
+		return 0, false
+	}
+	if amount > supply-deploy.Minted {
+		amount = supply - deploy.Minted
+	}
+	return amount, true
+}
+
+// brc20Transfer is a function that saves an inscribed brc20c transfer.
+// The amount moves from the available to the transferable balance of the inscription owner,
+// transfers above the available balance are ignored.
+func (p *Protocol) brc20Transfer(brc20 *util.CBRC20) error {
+	deploy, err := p.brc20Deploy(brc20)
+	if err != nil || deploy == nil {
+		return err
+	}
+	tkid := deploy.InscriptionId.String()
+
+	amount := gconv.Uint64(brc20.Amount)
+	balance, err := p.wtx.GetBalance(tkid, p.entry.Owner)
+	if err != nil {
+		return err
+	}
+	if amount > balance.Available {
+		log.Srv.Warnf("tkid: %s transfer amount %d exceeds available balance %d of %s", tkid, amount, balance.Available, p.entry.Owner)
+		return nil
+	}
+
+	if err := p.wtx.SaveProtocol(p.entry.Height, &tables.Protocol{
+		InscriptionId: tables.InscriptionId{
+			TxId:   p.entry.TxId,
+			Offset: p.entry.Offset,
+		},
+		Index:       p.entry.Index,
+		Owner:       p.entry.Owner,
+		SequenceNum: p.entry.SequenceNum,
+		Protocol:    brc20.Protocol,
+		Ticker:      brc20.Tick,
+		Operator:    brc20.Operation,
+		TkId:        tkid,
+		Amount:      amount,
+	}); err != nil {
+		return err
+	}
+	return p.wtx.UpdateBalance(p.entry.Height, tkid, deploy.Ticker, p.entry.Owner, -int64(amount), int64(amount))
+}
+
+// SendProtocol is a function that handles the first send of an inscribed transfer at the given height.
+// The transferable amount of the sender is credited to the available balance of the receiver,
+// after that the transfer inscription is spent and moving it again has no effect.
+// A transfer sent as fee, with an empty receiver, returns to the sender.
+// A transfer above the transferable balance of the sender is ignored.
+func (p *Protocol) SendProtocol(height uint32, to string) error {
+	if p.entry.ContentProtocol != constants.ProtocolCBRC20 {
+		return nil
+	}
+	transfer, err := p.wtx.GetProtocolByInscriptionId(&p.entry.InscriptionId)
+	if err != nil {
+		return err
+	}
+	if transfer.Id == 0 || transfer.Operator != constants.OperationTransfer || transfer.To != "" {
+		return nil
+	}
+	balance, err := p.wtx.GetBalance(transfer.TkId, transfer.Owner)
+	if err != nil {
+		return err
+	}
+	if transfer.Amount > balance.Transferable {
+		log.Srv.Warnf("tkid: %s transfer amount %d exceeds transferable balance %d of %s",
+			transfer.TkId, transfer.Amount, balance.Transferable, transfer.Owner)
+		return nil
+	}
+	if to == "" {
+		to = transfer.Owner
+	}
+	transfer.To = to
+	if err := p.wtx.SaveProtocol(height, &transfer); err != nil {
+		return err
+	}
+	amount := int64(transfer.Amount)
+	if err := p.wtx.UpdateBalance(height, transfer.TkId, transfer.Ticker, transfer.Owner, 0, -amount); err != nil {
+		return err
+	}
+	return p.wtx.UpdateBalance(height, transfer.TkId, transfer.Ticker, to, amount, 0)
+}
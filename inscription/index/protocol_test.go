@@ -0,0 +1,104 @@
+package index
+
+import (
+	"fmt"
+	"github.com/btcsuite/btclog"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/inscription/log"
+	"gotest.tools/assert"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestMintAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		deploy tables.Protocol
+		amount uint64
+		want   uint64
+		ok     bool
+	}{
+		{"within limit", tables.Protocol{Max: 100, Limit: 10}, 10, 10, true},
+		{"above limit", tables.Protocol{Max: 100, Limit: 10}, 11, 0, false},
+		{"truncated to max", tables.Protocol{Max: 100, Minted: 95}, 10, 5, true},
+		{"minted out", tables.Protocol{Max: 100, Minted: 100}, 1, 0, false},
+		{"no max", tables.Protocol{}, math.MaxUint64, math.MaxInt64, true},
+		{"no max minted out", tables.Protocol{Minted: math.MaxInt64}, 1, 0, false},
+		{"max above supply", tables.Protocol{Max: math.MaxUint64, Minted: math.MaxInt64 - 1}, 10, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, ok := mintAmount(&tt.deploy, tt.amount)
+			assert.Equal(t, ok, tt.ok)
+			assert.Equal(t, amount, tt.want)
+		})
+	}
+}
+
+// newTestDB returns a SQLite database with empty index tables in a temporary directory.
+func newTestDB(t *testing.T) *dao.DB {
+	db, err := dao.NewDB(
+		dao.WithBackend(dao.BackendSqlite),
+		dao.WithPath(filepath.Join(t.TempDir(), "cins.db")),
+		dao.WithAutoMigrateTables(tables.Tables...),
+	)
+	assert.NilError(t, err)
+	return db
+}
+
+func TestTransferProtocol(t *testing.T) {
+	db := newTestDB(t)
+	// the log rotator is not initialized in tests, the ignored operations are not logged
+	level := log.Srv.Level()
+	log.Srv.SetLevel(btclog.LevelOff)
+	defer log.Srv.SetLevel(level)
+
+	deployId := tables.NewInscriptionId(fmt.Sprintf("%064d", 1), 0).String()
+	inscribe := func(sequenceNum int64, owner, body string) *tables.Inscriptions {
+		entry := &tables.Inscriptions{
+			InscriptionId:   tables.InscriptionId{TxId: fmt.Sprintf("%064d", sequenceNum)},
+			SequenceNum:     sequenceNum,
+			Owner:           owner,
+			Height:          uint32(sequenceNum),
+			Body:            []byte(body),
+			ContentProtocol: constants.ProtocolCBRC20,
+		}
+		assert.NilError(t, NewProtocol(db, entry).SaveProtocol())
+		return entry
+	}
+	balance := func(address string) [2]uint64 {
+		balance, err := db.GetBalance(deployId, address)
+		assert.NilError(t, err)
+		return [2]uint64{balance.Available, balance.Transferable}
+	}
+
+	inscribe(1, "deployer", `{"p":"c-brc-20","op":"deploy","tick":"tick","max":"100","lim":"60"}`)
+	mint := fmt.Sprintf(`{"p":"c-brc-20","op":"mint","tick":"tick","tkid":"%s","amt":"60"}`, deployId)
+	inscribe(2, "minter", mint)
+	inscribe(3, "minter", mint)
+	deploy, err := db.GetProtocolByInscriptionId(tables.StringToInscriptionId(deployId))
+	assert.NilError(t, err)
+	assert.Equal(t, deploy.Minted, uint64(100))
+	assert.Equal(t, balance("minter"), [2]uint64{100, 0})
+
+	// A transfer above the available balance is ignored, the others lock their amount until they are sent once.
+	transfer := fmt.Sprintf(`{"p":"c-brc-20","op":"transfer","tick":"tick","tkid":"%s","amt":"%%d"}`, deployId)
+	inscribe(4, "minter", fmt.Sprintf(transfer, 101))
+	sent := inscribe(5, "minter", fmt.Sprintf(transfer, 30))
+	assert.Equal(t, balance("minter"), [2]uint64{70, 30})
+
+	// A send above the transferable balance of the sender is ignored instead of failing the block.
+	assert.NilError(t, db.UpdateBalance(6, deployId, "tick", "minter", 10, -10))
+	assert.NilError(t, NewProtocol(db, sent).SendProtocol(6, "receiver"))
+	assert.Equal(t, balance("receiver"), [2]uint64{0, 0})
+	assert.NilError(t, db.UpdateBalance(7, deployId, "tick", "minter", -10, 10))
+
+	assert.NilError(t, NewProtocol(db, sent).SendProtocol(8, "receiver"))
+	assert.NilError(t, NewProtocol(db, sent).SendProtocol(9, "other"))
+	assert.Equal(t, balance("minter"), [2]uint64{70, 0})
+	assert.Equal(t, balance("receiver"), [2]uint64{30, 0})
+	assert.Equal(t, balance("other"), [2]uint64{0, 0})
+}
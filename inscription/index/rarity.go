@@ -44,3 +44,22 @@ func NewRarityFromSat(sat *Sat) Rarity {
 	}
 	return r // Return the determined rarity
 }
+
+// String is a method that returns the name of a Rarity.
+func (r Rarity) String() string {
+	switch r {
+	case RarityCommon:
+		return "common"
+	case RarityUncommon:
+		return "uncommon"
+	case RarityRare:
+		return "rare"
+	case RarityEpic:
+		return "epic"
+	case RarityLegendary:
+		return "legendary"
+	case RarityMythic:
+		return "mythic"
+	}
+	return ""
+}
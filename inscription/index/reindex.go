@@ -0,0 +1,200 @@
+// Package index provides the re-indexing of a range of blocks.
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/inscription/log"
+	"sort"
+)
+
+// ReindexReport is the result of ReindexRange.
+type ReindexReport struct {
+	From           uint32                  `json:"from"`
+	To             uint32                  `json:"to"`
+	RollbackHeight uint32                  `json:"rollback_height"` // height of the last block kept by the rollback, the blocks after it are replayed
+	IndexHeight    uint32                  `json:"index_height"`    // height of the last indexed block before the re-index
+	DryRun         bool                    `json:"dry_run"`
+	Diff           map[string]*ReindexDiff `json:"diff,omitempty"` // differences by table, only for a dry run
+}
+
+// ReindexDiff are the rows of a table that differ between the existing and the re-indexed data.
+// Rows are compared without their id and timestamps.
+type ReindexDiff struct {
+	Added   []map[string]interface{} `json:"added"`   // rows only in the re-indexed data
+	Removed []map[string]interface{} `json:"removed"` // rows only in the existing data
+}
+
+// reindexIgnoredColumns are not compared by a dry run, they differ between runs of the same blocks.
+var reindexIgnoredColumns = []string{"id", "created_at", "updated_at"}
+
+// ReindexRange re-indexes the blocks from height from to height to, for example after a fix of the envelope parser.
+// The index is rolled back to the block before from, see rollbackTo: with the undo logs to the newest savepoint
+// below from if the range is within the reorg protection window, otherwise by deleting the blocks after it.
+// The blocks after the rollback are then indexed again up to to, the blocks after to are indexed again by the indexer.
+//
+// A dry run re-indexes in a transaction that is rolled back, and reports the rows of the blocks in the range
+// that differ between the existing and the re-indexed data, see BlockRows. The existing data of an index above
+// to is first rolled back to to with DeleteBlocksAfter, so that the rows changed by the later blocks, such as
+// the current owners and the balances, are compared at the same height. The indexer must not be running.
+func (idx *Indexer) ReindexRange(from, to uint32, dryRun bool) (*ReindexReport, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("invalid re-index range [%d, %d]", from, to)
+	}
+	if err := idx.loadSettings(); err != nil {
+		return nil, err
+	}
+	count, err := idx.DB().BlockCount()
+	if err != nil {
+		return nil, err
+	}
+	if from >= count {
+		return nil, fmt.Errorf("block %d is not indexed, the index is at height %d", from, int64(count)-1)
+	}
+	nodeHeight, err := idx.RpcClient().GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+	if int64(to) > nodeHeight {
+		return nil, fmt.Errorf("block %d is above the node tip %d", to, nodeHeight)
+	}
+
+	report := &ReindexReport{
+		From:        from,
+		To:          to,
+		IndexHeight: count - 1,
+		DryRun:      dryRun,
+	}
+	if dryRun {
+		return report, idx.reindexDryRun(report)
+	}
+
+	log.Srv.Infof("re-indexing blocks %d to %d", from, to)
+	if err := idx.DB().Transaction(func(tx *dao.DB) error {
+		report.RollbackHeight, err = idx.rollbackTo(tx, from-1)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	idx.resetCaches()
+	log.Srv.Infof("rolled back index to height %d", report.RollbackHeight)
+
+	idx.StopAt(to)
+	defer idx.stopHeight.Store(-1)
+	if err := idx.UpdateIndex(); err != nil {
+		return nil, err
+	}
+	height, err := idx.DB().BlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	if height != to {
+		return nil, fmt.Errorf("re-index stopped at height %d", height)
+	}
+	return report, nil
+}
+
+// reindexDryRun rolls back and re-indexes in a transaction that is rolled back, diffing the rows of the blocks in the range.
+func (idx *Indexer) reindexDryRun(report *ReindexReport) error {
+	wtx := idx.Begin()
+	defer wtx.Rollback()
+	defer idx.resetCaches()
+
+	if report.IndexHeight > report.To {
+		if err := wtx.DeleteBlocksAfter(report.To); err != nil {
+			return fmt.Errorf("roll back to height %d to compare the blocks: %w", report.To, err)
+		}
+		if err := restoreInscriptionCounters(wtx, report.To); err != nil {
+			return err
+		}
+	}
+	before, err := wtx.BlockRows(report.From, report.To)
+	if err != nil {
+		return err
+	}
+	report.RollbackHeight, err = idx.rollbackTo(wtx, report.From-1)
+	if err != nil {
+		return err
+	}
+	idx.resetCaches()
+
+	idx.height = report.RollbackHeight + 1
+	for idx.height <= report.To {
+		block, err := idx.getBlockWithRetries(idx.height)
+		if err != nil {
+			return err
+		}
+		if err := idx.indexBlock(wtx, block); err != nil {
+			return err
+		}
+	}
+	if err := idx.flush(wtx); err != nil {
+		return err
+	}
+
+	after, err := wtx.BlockRows(report.From, report.To)
+	if err != nil {
+		return err
+	}
+	report.Diff, err = diffRows(before, after)
+	return err
+}
+
+// diffRows returns the rows of each table that are only in before or only in after.
+func diffRows(before, after map[string][]map[string]interface{}) (map[string]*ReindexDiff, error) {
+	type entry struct {
+		row   map[string]interface{}
+		count int
+	}
+	diff := make(map[string]*ReindexDiff)
+	for _, table := range tables.Tables {
+		name := table.(interface{ TableName() string }).TableName()
+		// Rows before count -1 and rows after +1, so rows in both cancel out.
+		entries := make(map[string]*entry)
+		for i, rows := range [][]map[string]interface{}{before[name], after[name]} {
+			for _, row := range rows {
+				for _, column := range reindexIgnoredColumns {
+					delete(row, column)
+				}
+				data, err := json.Marshal(row)
+				if err != nil {
+					return nil, err
+				}
+				e, ok := entries[string(data)]
+				if !ok {
+					e = &entry{row: row}
+					entries[string(data)] = e
+				}
+				e.count += 2*i - 1
+			}
+		}
+
+		keys := make([]string, 0, len(entries))
+		for key, e := range entries {
+			if e.count != 0 {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+		d := &ReindexDiff{
+			Added:   make([]map[string]interface{}, 0),
+			Removed: make([]map[string]interface{}, 0),
+		}
+		for _, key := range keys {
+			e := entries[key]
+			for ; e.count > 0; e.count-- {
+				d.Added = append(d.Added, e.row)
+			}
+			for ; e.count < 0; e.count++ {
+				d.Removed = append(d.Removed, e.row)
+			}
+		}
+		diff[name] = d
+	}
+	return diff, nil
+}
@@ -0,0 +1,39 @@
+package index
+
+import (
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestDiffRows(t *testing.T) {
+	before := map[string][]map[string]interface{}{
+		"inscriptions": {
+			{"id": 1, "sequence_num": 1, "inscription_num": 0},
+			{"id": 2, "sequence_num": 2, "inscription_num": 1},
+		},
+		"protocol": {
+			{"id": 1, "sequence_num": 1, "operator": "transfer", "to": "owner"},
+		},
+	}
+	after := map[string][]map[string]interface{}{
+		"inscriptions": {
+			{"id": 3, "sequence_num": 1, "inscription_num": 0},
+			{"id": 4, "sequence_num": 2, "inscription_num": -1},
+		},
+		"protocol": {
+			{"id": 2, "sequence_num": 1, "operator": "transfer", "to": ""},
+		},
+	}
+	diff, err := diffRows(before, after)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, diff, map[string]*ReindexDiff{
+		"inscriptions": {
+			Added:   []map[string]interface{}{{"sequence_num": 2, "inscription_num": -1}},
+			Removed: []map[string]interface{}{{"sequence_num": 2, "inscription_num": 1}},
+		},
+		"protocol": {
+			Added:   []map[string]interface{}{{"sequence_num": 1, "operator": "transfer", "to": ""}},
+			Removed: []map[string]interface{}{{"sequence_num": 1, "operator": "transfer", "to": "owner"}},
+		},
+	})
+}
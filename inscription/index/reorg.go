@@ -8,14 +8,19 @@ import (
 	"github.com/inscription-c/cins/inscription/index/dao"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"github.com/inscription-c/cins/inscription/log"
+	"time"
 )
 
+// Default reorg protection, reorgs up to 10 blocks deep can be rolled back.
 const (
-	maxSavepoint      uint32 = 2
-	savepointInterval uint32 = 10
-	chainTipDistance  uint32 = 21
+	defaultMaxSavepoint      uint32 = 2
+	defaultSavepointInterval uint32 = 10
+	defaultChainTipDistance  uint32 = 21
 )
 
+// reorgSubBuffer is the number of reorg events buffered for a subscriber before events are dropped.
+const reorgSubBuffer = 16
+
 var ErrDetectReorg = errors.New("unrecoverable reorg detected")
 
 type ErrRecoverable struct {
@@ -27,6 +32,43 @@ func (r *ErrRecoverable) Error() string {
 	return fmt.Sprintf("recoverable reorg detected at height %d and depth %d", r.Height, r.Depth)
 }
 
+// ReorgEvent is a chain reorganization handled by the Indexer.
+type ReorgEvent struct {
+	Height      uint32 `json:"height"`       // height of the block that did not connect to the index
+	Depth       uint32 `json:"depth"`        // number of blocks reorganized, 0 if the reorg is unrecoverable
+	Recoverable bool   `json:"recoverable"`  // whether the index was rolled back to a savepoint
+	Reindex     bool   `json:"reindex"`      // whether the index was reset to be re-indexed
+	IndexHeight uint32 `json:"index_height"` // height indexing continues from
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// SubscribeReorg is a method that subscribes to the reorg events of the Indexer.
+// Events are dropped if the subscriber falls behind. The returned function cancels the subscription.
+func (idx *Indexer) SubscribeReorg() (<-chan *ReorgEvent, func()) {
+	ch := make(chan *ReorgEvent, reorgSubBuffer)
+	idx.reorgSubsMu.Lock()
+	idx.reorgSubs[ch] = struct{}{}
+	idx.reorgSubsMu.Unlock()
+	return ch, func() {
+		idx.reorgSubsMu.Lock()
+		delete(idx.reorgSubs, ch)
+		idx.reorgSubsMu.Unlock()
+	}
+}
+
+// publishReorg sends a reorg event to the subscribers without blocking the Indexer.
+func (idx *Indexer) publishReorg(event *ReorgEvent) {
+	event.Timestamp = time.Now().Unix()
+	idx.reorgSubsMu.Lock()
+	defer idx.reorgSubsMu.Unlock()
+	for ch := range idx.reorgSubs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
 // detectReorg is a function that detects a blockchain reorganization.
 // It takes a pointer to a DB, a pointer to a MsgBlock, and a uint32 as parameters.
 // The function first gets the previous block hash from the block header and assigns it to bitcoindPrevBlockHash.
@@ -36,7 +78,7 @@ func (r *ErrRecoverable) Error() string {
 // The function then compares indexPreBlockHash with bitcoindPrevBlockHash.
 // If they are equal, it returns nil because there is no reorganization.
 // If they are not equal, it returns an error because a reorganization is detected.
-func detectReorg(index *Indexer, wtx *dao.DB, block *wire.MsgBlock, height uint32) error {
+func detectReorg(index *Indexer, wtx dao.Store, block *wire.MsgBlock, height uint32) error {
 	bitcoindPrevBlockHash := block.Header.PrevBlock.String()
 	if height == 0 {
 		return nil
@@ -49,6 +91,7 @@ func detectReorg(index *Indexer, wtx *dao.DB, block *wire.MsgBlock, height uint3
 		return nil
 	}
 
+	maxSavepoint, savepointInterval := index.opts.maxSavepoint, index.opts.savepointInterval
 	maxRecoverableReorgDepth := (maxSavepoint-1)*savepointInterval + height%savepointInterval
 	for depth := uint32(1); depth < maxRecoverableReorgDepth; depth++ {
 		if height < depth {
@@ -77,16 +120,15 @@ func detectReorg(index *Indexer, wtx *dao.DB, block *wire.MsgBlock, height uint3
 // If there is an error getting the savepoints, it returns the error.
 // The function then checks if the height is less than the savepoint interval or if the height is a multiple of the savepoint interval.
 // It also checks if the difference between the number of headers in the blockchain info and the height is less than or equal to the chain tip distance.
-// If both conditions are true, it checks if the length of the savepoints is greater than or equal to the maximum savepoint.
-// If it is, it deletes the first savepoint and all undo logs with an id less than or equal to the id of the undo log of the first savepoint.
-// It then gets the last undo log and assigns it to latest.
-// If there is an error getting the last undo log, it returns the error.
+// If both conditions are true, it gets the id of the latest undo log and assigns it to latest.
+// If the number of savepoints reached the maximum savepoint, it deletes the oldest savepoints
+// and all undo logs up to the undo log of the oldest savepoint kept.
 // The function then creates a new savepoint with the height and the id of the latest undo log.
 // If there is an error creating the new savepoint, it returns the error.
 // If the length of the savepoints is zero, it deletes all undo logs.
 // If there is an error deleting the undo logs, it returns the error.
 // The function then returns nil.
-func updateSavePoints(index *Indexer, wtx *dao.DB, height uint32) error {
+func updateSavePoints(index *Indexer, wtx dao.Store, height uint32) error {
 	chainInfo, err := index.opts.cli.GetBlockChainInfo()
 	if err != nil {
 		return err
@@ -96,30 +138,30 @@ func updateSavePoints(index *Indexer, wtx *dao.DB, height uint32) error {
 		return err
 	}
 
-	if (height < savepointInterval || height%savepointInterval == 0) &&
-		chainInfo.Headers-int32(height) <= int32(chainTipDistance) {
-		if len(savepoints) >= int(maxSavepoint) {
-			// delete oldest savepoint
-			if err := wtx.Delete(&tables.SavePoint{
-				Id: savepoints[0].Id,
-			}).Error; err != nil {
-				return err
+	if (height < index.opts.savepointInterval || height%index.opts.savepointInterval == 0) &&
+		chainInfo.Headers-int32(height) <= int32(index.opts.chainTipDistance) {
+		latest, err := wtx.LatestUndoLogId()
+		if err != nil {
+			return err
+		}
+		if len(savepoints) >= int(index.opts.maxSavepoint) {
+			// delete the oldest savepoints, keeping maxSavepoint savepoints with the new one
+			expired := savepoints[:len(savepoints)-int(index.opts.maxSavepoint)+1]
+			ids := make([]uint64, 0, len(expired))
+			for _, savepoint := range expired {
+				ids = append(ids, savepoint.Id)
+			}
+			undoLogId := latest
+			if len(expired) < len(savepoints) {
+				undoLogId = savepoints[len(expired)].UndoLogId
 			}
-			if err := wtx.Where("id<=?", savepoints[1].UndoLogId).
-				Delete(&tables.UndoLog{}).Error; err != nil {
+			if err := wtx.ExpireSavepoints(ids, undoLogId); err != nil {
 				return err
 			}
 		}
-		latest := &tables.UndoLog{}
-		if err := wtx.Last(latest).Error; err != nil {
-			return err
-		}
 
 		log.Srv.Infof("creating savepoint at height %d", height)
-		return wtx.Create(&tables.SavePoint{
-			Height:    height,
-			UndoLogId: latest.Id,
-		}).Error
+		return wtx.CreateSavepoint(height, latest)
 	}
 	if len(savepoints) == 0 {
 		if err := wtx.DeleteUndoLog(); err != nil {
@@ -130,60 +172,129 @@ func updateSavePoints(index *Indexer, wtx *dao.DB, height uint32) error {
 }
 
 // handleReorg is a function that handles a blockchain reorganization.
-// It takes a pointer to an Indexer, a uint32 for the height, and a uint32 for the depth as parameters.
-// The function first logs the depth and height of the reorganization.
-// It then starts a transaction on the DB of the Indexer.
-// Inside the transaction, it gets the undo log and assigns it to undoLog.
-// If there is an error getting the undo log, it returns the error.
-// The function then executes each SQL statement in the undo log.
-// If there is an error executing a SQL statement, it returns the error.
-// The function then deletes the undo log.
-// If there is an error deleting the undo log, it returns the error.
-// The function then deletes the savepoint.
-// If there is an error deleting the savepoint, it returns the error.
-// If there is an error with the transaction, it returns the error.
-// The function then gets the block count from the DB of the Indexer and assigns it to indexHeight.
-// If there is an error getting the block count, it returns the error.
-// The function then logs the height of the blockchain after the rollback.
-// The function then returns nil.
+// It rolls the index back to the newest savepoint at or below the last block shared with the node,
+// undoing the changes recorded in the undo logs after the savepoint.
+// The blocks after the savepoint are indexed again on the next update.
 func handleReorg(index *Indexer, height, depth uint32) error {
 	log.Srv.Infof("rolling back database after reorg of depth %d at height %d", depth, height)
 	if err := index.DB().Transaction(func(tx *dao.DB) error {
-		oldestSavepoint, err := tx.OldestSavepoint()
-		if err != nil {
-			return err
-		}
-		if oldestSavepoint.Id == 0 {
-			return errors.New("no savepoint found")
-		}
-
-		rows, err := index.DB().FindUndoLog()
+		savepoint, err := tx.SavepointAtHeight(height - depth)
 		if err != nil {
 			return err
 		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var undoLog tables.UndoLog
-			if err := index.DB().ScanRows(rows, &undoLog); err != nil {
-				return err
-			}
-			if err := tx.Exec(undoLog.Sql).Error; err != nil {
-				return err
-			}
-		}
-		if err := tx.DeleteUndoLog(); err != nil {
-			return err
+		if savepoint.Id == 0 {
+			return fmt.Errorf("no savepoint found at or below height %d", height-depth)
 		}
-		return tx.DeleteSavepoint(oldestSavepoint.Id)
+		return tx.RollbackToSavepoint(&savepoint)
 	}); err != nil {
 		return err
 	}
 
+	index.resetCaches()
+
 	indexHeight, err := index.DB().BlockCount()
 	if err != nil {
 		return err
 	}
 	log.Srv.Infof("successfully rolled back database to height %d", indexHeight)
+	index.publishReorg(&ReorgEvent{
+		Height:      height,
+		Depth:       depth,
+		Recoverable: true,
+		IndexHeight: indexHeight,
+	})
+	return nil
+}
+
+// reindex rolls the index back after an unrecoverable reorg, so it is indexed again from the reorg re-index height.
+// The block below the re-index height must be on the chain of the node, otherwise the reorg is deeper than it.
+func (idx *Indexer) reindex() error {
+	height := idx.height
+	reindexHeight := uint32(idx.opts.reorgReindexHeight)
+	if reindexHeight >= height {
+		return fmt.Errorf("reorg re-index height %d is not below the reorg height %d", reindexHeight, height)
+	}
+	indexBlockHash, err := idx.DB().BlockHash(reindexHeight - 1)
+	if err != nil {
+		return err
+	}
+	bitcoindBlockHash, err := idx.RpcClient().GetBlockHash(int64(reindexHeight - 1))
+	if err != nil {
+		return err
+	}
+	if indexBlockHash != bitcoindBlockHash.String() {
+		return fmt.Errorf("reorg at height %d is deeper than the reorg re-index height %d", height, reindexHeight)
+	}
+
+	log.Srv.Warnf("rolling back index after unrecoverable reorg at height %d, re-indexing from height %d", height, reindexHeight)
+	var indexHeight uint32
+	if err := idx.DB().Transaction(func(tx *dao.DB) error {
+		indexHeight, err = idx.rollbackTo(tx, reindexHeight-1)
+		return err
+	}); err != nil {
+		return err
+	}
+	idx.resetCaches()
+	idx.publishReorg(&ReorgEvent{
+		Height:      height,
+		Reindex:     true,
+		IndexHeight: indexHeight + 1,
+	})
 	return nil
 }
+
+// rollbackTo rolls the index back so that it is indexed up to at most the block at the given height.
+// It rolls back to the newest savepoint at or below the height with the undo logs if there is one,
+// otherwise it deletes the blocks after the height and recounts the inscriptions, which fails if
+// the index can not be rebuilt at it.
+// It returns the height of the last block indexed after the rollback.
+func (idx *Indexer) rollbackTo(tx dao.Store, height uint32) (uint32, error) {
+	savepoint, err := tx.SavepointAtHeight(height)
+	if err != nil {
+		return 0, err
+	}
+	if savepoint.Id > 0 {
+		if err := tx.RollbackToSavepoint(&savepoint); err != nil {
+			return 0, err
+		}
+		return tx.BlockHeight()
+	}
+	if err := tx.DeleteBlocksAfter(height); err != nil {
+		return 0, err
+	}
+	if err := restoreInscriptionCounters(tx, height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// restoreInscriptionCounters sets the inscription counters to the inscriptions left at the given height.
+func restoreInscriptionCounters(tx dao.Store, height uint32) error {
+	counts, err := tx.CountInscriptions(CharmUnbound.Flag())
+	if err != nil {
+		return err
+	}
+	for _, counter := range []struct {
+		name  tables.StatisticType
+		count uint64
+	}{
+		{tables.StatisticBlessedInscriptions, counts.Blessed},
+		{tables.StatisticCursedInscriptions, counts.Cursed},
+		{tables.StatisticUnboundInscriptions, counts.Unbound},
+	} {
+		if err := tx.SetStatistic(height, counter.name, counter.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resetCaches drops the cached values and sat ranges of blocks that were not committed.
+func (idx *Indexer) resetCaches() {
+	idx.valueCache = NewValueCache()
+	idx.rangeCache = NewRangeCaches()
+	idx.outputsCached = 0
+	idx.outputsTraversed = 0
+	idx.outputsInsertedSinceFlush = 0
+	idx.satRangesSinceFlush = 0
+}
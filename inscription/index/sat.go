@@ -2,8 +2,14 @@
 package index
 
 import (
+	"errors"
+	"fmt"
 	"github.com/inscription-c/cins/constants"
 	"github.com/shopspring/decimal"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // Constants representing the total supply of Sat and the last supply of Sat.
@@ -39,6 +45,30 @@ func (s *Sat) Degree() *Degree {
 	return NewDegreeFromSat(s)
 }
 
+// Decimal returns the decimal notation of the Sat, which is the height of the block
+// it was mined in and its offset within the block subsidy, such as 1.0.
+func (s *Sat) Decimal() string {
+	return fmt.Sprintf("%d.%d", s.Height().N(), s.Third())
+}
+
+// Percentile returns the position of the Sat in the total supply as a percentage, such as 100%.
+func (s *Sat) Percentile() string {
+	return strconv.FormatFloat(float64(*s)/float64(LastSupplySat)*100, 'f', -1, 64) + "%"
+}
+
+// Name returns the base-26 name of the Sat. Names count down from the last sat,
+// so the last sat mined is named "a" and the first sat mined is named "nvtdijuwxlp".
+func (s *Sat) Name() string {
+	x := SupplySat - uint64(*s)
+	name := make([]byte, 0, 11)
+	for x > 0 {
+		name = append(name, 'a'+byte((x-1)%26))
+		x = (x - 1) / 26
+	}
+	slices.Reverse(name)
+	return string(name)
+}
+
 // Rarity returns the Rarity of the Sat.
 func (s *Sat) Rarity() Rarity {
 	return NewRarityFromSat(s)
@@ -56,6 +86,29 @@ func (s *Sat) Coin() bool {
 	return uint64(*s)%constants.OneBtc == 0
 }
 
+// Charms returns the charms that a Sat carries by itself, based on its position and rarity.
+func (s *Sat) Charms() uint16 {
+	charms := uint16(0)
+	if s.NineBall() {
+		CharmNineBall.Set(&charms)
+	}
+	if s.Coin() {
+		CharmCoin.Set(&charms)
+	}
+	switch s.Rarity() {
+	case RarityCommon, RarityMythic:
+	case RarityUncommon:
+		CharmUncommon.Set(&charms)
+	case RarityRare:
+		CharmRare.Set(&charms)
+	case RarityEpic:
+		CharmEpic.Set(&charms)
+	case RarityLegendary:
+		CharmLegendary.Set(&charms)
+	}
+	return charms
+}
+
 // Height returns the Height of the Sat.
 // It calculates the Height by adding the starting height of the epoch to the epoch position divided by the epoch subsidy.
 func (s *Sat) Height() *Height {
@@ -88,3 +141,130 @@ func (s *Sat) Common() bool {
 	startingSat := epoch.StartingSat()
 	return (s.N()-startingSat.N())%epoch.Subsidy() != 0
 }
+
+// ParseSat parses a Sat in one of the following notations:
+// integer (2099999997689999), decimal (6929999.0), degree (5°209999′1007″0‴),
+// percentile (100%) or name (a).
+func ParseSat(s string) (Sat, error) {
+	switch {
+	case strings.IndexFunc(s, func(r rune) bool { return r >= 'a' && r <= 'z' }) >= 0:
+		return parseSatName(s)
+	case strings.Contains(s, "°"):
+		return parseSatDegree(s)
+	case strings.Contains(s, "%"):
+		return parseSatPercentile(s)
+	case strings.Contains(s, "."):
+		return parseSatDecimal(s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n > LastSupplySat {
+		return 0, fmt.Errorf("invalid sat: %s", s)
+	}
+	return Sat(n), nil
+}
+
+// parseSatName parses the base-26 name of a sat, where "a" is the last sat mined.
+func parseSatName(s string) (Sat, error) {
+	x := uint64(0)
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return 0, fmt.Errorf("invalid character in sat name: %c", c)
+		}
+		x = x*26 + uint64(c-'a') + 1
+		if x > SupplySat {
+			return 0, fmt.Errorf("sat name out of range: %s", s)
+		}
+	}
+	return Sat(SupplySat - x), nil
+}
+
+// parseSatPercentile parses the position of a sat in the total supply as a percentage.
+func parseSatPercentile(s string) (Sat, error) {
+	percentileStr, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return 0, fmt.Errorf("invalid sat percentile: %s", s)
+	}
+	percentile, err := strconv.ParseFloat(percentileStr, 64)
+	if err != nil || percentile < 0 || math.IsNaN(percentile) {
+		return 0, fmt.Errorf("invalid sat percentile: %s", s)
+	}
+	n := math.Round(percentile / 100 * float64(LastSupplySat))
+	if n > float64(LastSupplySat) {
+		return 0, fmt.Errorf("sat percentile out of range: %s", s)
+	}
+	return Sat(n), nil
+}
+
+// parseSatDecimal parses a sat in decimal notation, the block height and the offset of the sat in its subsidy.
+func parseSatDecimal(s string) (Sat, error) {
+	heightStr, offsetStr, _ := strings.Cut(s, ".")
+	height, err := strconv.ParseUint(heightStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sat height: %s", heightStr)
+	}
+	offset, err := strconv.ParseUint(offsetStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sat offset: %s", offsetStr)
+	}
+	h := NewHeight(uint32(height))
+	if offset >= h.Subsidy() {
+		return 0, errors.New("sat offset exceeds block subsidy")
+	}
+	return h.StartingSat() + Sat(offset), nil
+}
+
+// parseSatDegree parses a sat in degree notation, cycle°epoch offset′period offset″sat offset‴.
+func parseSatDegree(s string) (Sat, error) {
+	cycleStr, rest, _ := strings.Cut(s, "°")
+	epochOffsetStr, rest, ok := strings.Cut(rest, "′")
+	if !ok {
+		return 0, errors.New("missing minute symbol in sat degree")
+	}
+	periodOffsetStr, rest, ok := strings.Cut(rest, "″")
+	if !ok {
+		return 0, errors.New("missing second symbol in sat degree")
+	}
+	satOffsetStr := "0"
+	if rest != "" {
+		if satOffsetStr, ok = strings.CutSuffix(rest, "‴"); !ok {
+			return 0, errors.New("missing third symbol in sat degree")
+		}
+	}
+
+	cycle, err := strconv.ParseUint(cycleStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sat cycle: %s", cycleStr)
+	}
+	epochOffset, err := strconv.ParseUint(epochOffsetStr, 10, 32)
+	if err != nil || epochOffset >= uint64(SubsidyHalvingInterval) {
+		return 0, fmt.Errorf("invalid sat epoch offset: %s", epochOffsetStr)
+	}
+	periodOffset, err := strconv.ParseUint(periodOffsetStr, 10, 32)
+	if err != nil || periodOffset >= uint64(DiffChangeInterval) {
+		return 0, fmt.Errorf("invalid sat period offset: %s", periodOffsetStr)
+	}
+	satOffset, err := strconv.ParseUint(satOffsetStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sat offset: %s", satOffsetStr)
+	}
+
+	// The epoch within the cycle is the one where the epoch offset and the
+	// period offset describe the same block height.
+	halvingIncrement := uint64(SubsidyHalvingInterval % DiffChangeInterval)
+	relationship := periodOffset + uint64(SubsidyHalvingInterval*CycleEpochs) - epochOffset
+	if relationship%halvingIncrement != 0 {
+		return 0, errors.New("relationship between epoch offset and period offset is invalid")
+	}
+	epochsSinceCycleStart := relationship % uint64(DiffChangeInterval) / halvingIncrement
+	epoch := cycle*uint64(CycleEpochs) + epochsSinceCycleStart
+	height := epoch*uint64(SubsidyHalvingInterval) + epochOffset
+	if height > uint64(^uint32(0)) {
+		return 0, fmt.Errorf("sat degree out of range: %s", s)
+	}
+
+	h := NewHeight(uint32(height))
+	if satOffset >= h.Subsidy() {
+		return 0, errors.New("sat offset exceeds block subsidy")
+	}
+	return h.StartingSat() + Sat(satOffset), nil
+}
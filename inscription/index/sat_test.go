@@ -0,0 +1,91 @@
+package index
+
+import (
+	"testing"
+)
+
+func TestParseSat(t *testing.T) {
+	tests := []struct {
+		input string
+		sat   Sat
+		ok    bool
+	}{
+		{"0", 0, true},
+		{"1", 1, true},
+		{"2099999997689999", LastSupplySat, true},
+		{"2099999997690000", 0, false},
+		{"0.0", 0, true},
+		{"0.1", 1, true},
+		{"1.0", 50 * 100_000_000, true},
+		{"6929999.0", LastSupplySat, true},
+		{"6930000.0", 0, false},
+		{"0.5000000000", 0, false},
+		{"0°0′0″0‴", 0, true},
+		{"0°1′1″0‴", 50 * 100_000_000, true},
+		{"1°0′0″0‴", 2067187500000000, true},
+		{"5°209999′1007″0‴", LastSupplySat, true},
+		{"3°0′0″", 2099991988080000, true},
+		{"0°1′2″0‴", 0, false},
+		{"0°1″0‴", 0, false},
+		{"0%", 0, true},
+		{"100%", LastSupplySat, true},
+		{"100.1%", 0, false},
+		{"-1%", 0, false},
+		{"nvtdijuwxlp", 0, true},
+		{"nvtdijuwxlo", 1, true},
+		{"a", LastSupplySat, true},
+		{"z", LastSupplySat - 25, true},
+		{"aa", LastSupplySat - 26, true},
+		{"nvtdijuwxlq", 0, false},
+		{"A", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sat, err := ParseSat(tt.input)
+			if (err == nil) != tt.ok {
+				t.Fatalf("parse %s: got err %v, want ok %v", tt.input, err, tt.ok)
+			}
+			if tt.ok && sat != tt.sat {
+				t.Fatalf("parse %s: got %d, want %d", tt.input, sat, tt.sat)
+			}
+		})
+	}
+}
+
+func TestSatNotations(t *testing.T) {
+	tests := []struct {
+		sat        Sat
+		name       string
+		decimal    string
+		degree     string
+		percentile string
+	}{
+		{0, "nvtdijuwxlp", "0.0", "0°0′0″0‴", "0%"},
+		{1, "nvtdijuwxlo", "0.1", "0°0′0″1‴", "0.000000000000047619047671428595%"},
+		{26, "nvtdijuwxkp", "0.26", "0°0′0″26‴", ""},
+		{27, "nvtdijuwxko", "0.27", "0°0′0″27‴", ""},
+		{50 * 100_000_000, "nvtcsezkbth", "1.0", "0°1′1″0‴", ""},
+		{LastSupplySat / 2, "gkjbdrjxyfi", "209999.4998844999", "0°209999′335″4998844999‴", "49.99999999999998%"},
+		{LastSupplySat, "a", "6929999.0", "5°209999′1007″0‴", "100%"},
+	}
+	for _, tt := range tests {
+		if name := tt.sat.Name(); name != tt.name {
+			t.Errorf("sat %d: got name %s, want %s", tt.sat, name, tt.name)
+		}
+		if decimal := tt.sat.Decimal(); decimal != tt.decimal {
+			t.Errorf("sat %d: got decimal %s, want %s", tt.sat, decimal, tt.decimal)
+		}
+		if degree := tt.sat.Degree().String(); degree != tt.degree {
+			t.Errorf("sat %d: got degree %s, want %s", tt.sat, degree, tt.degree)
+		}
+		if tt.percentile != "" && tt.sat.Percentile() != tt.percentile {
+			t.Errorf("sat %d: got percentile %s, want %s", tt.sat, tt.sat.Percentile(), tt.percentile)
+		}
+		for _, notation := range []string{tt.name, tt.decimal, tt.degree} {
+			if sat, err := ParseSat(notation); err != nil || sat != tt.sat {
+				t.Errorf("parse %s: got %d, %v, want %d", notation, sat, err, tt.sat)
+			}
+		}
+	}
+}
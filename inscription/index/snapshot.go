@@ -0,0 +1,221 @@
+// Package index provides the export and import of index snapshots.
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/util"
+	"hash"
+	"io"
+	"reflect"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by ExportSnapshot.
+// Snapshots of other versions are rejected on import.
+const SnapshotVersion uint32 = 1
+
+const (
+	// snapshotMagic starts every snapshot, before the compressed data.
+	snapshotMagic = "CINSSNAP"
+	// snapshotBatchSize is the number of rows read and written at once per table.
+	snapshotBatchSize = 1000
+)
+
+var (
+	ErrSnapshotFormat   = errors.New("not an index snapshot")
+	ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+)
+
+// SnapshotHeader describes the index stored in a snapshot.
+type SnapshotHeader struct {
+	Version   uint32   `json:"version"`
+	Network   string   `json:"network"`
+	Height    uint32   `json:"height"`     // height of the last indexed block
+	BlockHash string   `json:"block_hash"` // hash of the last indexed block
+	CreatedAt int64    `json:"created_at"`
+	Tables    []string `json:"tables"` // tables in the order they are stored
+}
+
+// snapshotTrailer ends a snapshot.
+// The checksum is the SHA-256 of the uncompressed data before the trailer.
+type snapshotTrailer struct {
+	Rows     map[string]uint64
+	Checksum []byte
+}
+
+// ExportSnapshot writes all tables of the index at the last committed block to w.
+//
+// A snapshot is the magic followed by a gzip stream of gob values: the header,
+// then per table in the order of the header a sequence of row batches ended by
+// an empty batch, then the trailer with the row counts and the checksum.
+// The tables are read in one transaction, so a running indexer does not change the snapshot.
+func ExportSnapshot(db *dao.DB, w io.Writer) (header *SnapshotHeader, err error) {
+	if _, err = io.WriteString(w, snapshotMagic); err != nil {
+		return
+	}
+	gz := gzip.NewWriter(w)
+	checksum := sha256.New()
+	enc := gob.NewEncoder(io.MultiWriter(gz, checksum))
+
+	err = db.Transaction(func(tx *dao.DB) error {
+		count, err := tx.BlockCount()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("index is empty")
+		}
+		blockHash, err := tx.BlockHash(count - 1)
+		if err != nil {
+			return err
+		}
+		header = &SnapshotHeader{
+			Version:   SnapshotVersion,
+			Network:   util.ActiveNet.Name,
+			Height:    count - 1,
+			BlockHash: blockHash,
+			CreatedAt: time.Now().Unix(),
+		}
+		for _, table := range tables.Tables {
+			header.Tables = append(header.Tables, table.(interface{ TableName() string }).TableName())
+		}
+		if err := enc.Encode(header); err != nil {
+			return err
+		}
+
+		trailer := &snapshotTrailer{Rows: make(map[string]uint64)}
+		for i, table := range tables.Tables {
+			if err := tx.ExportTable(table, snapshotBatchSize, func(rows interface{}) error {
+				trailer.Rows[header.Tables[i]] += uint64(reflect.Indirect(reflect.ValueOf(rows)).Len())
+				return enc.Encode(rows)
+			}); err != nil {
+				return fmt.Errorf("export %s: %w", header.Tables[i], err)
+			}
+			if err := enc.Encode(reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(table)), 0, 0).Interface()); err != nil {
+				return err
+			}
+		}
+		trailer.Checksum = checksum.Sum(nil)
+		return enc.Encode(trailer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header, gz.Close()
+}
+
+// checksumReader hashes the bytes read from a buffered reader.
+// It implements io.ByteReader, so gob decodes from it without reading ahead.
+type checksumReader struct {
+	r *bufio.Reader
+	h hash.Hash
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.h.Write(p[:n])
+	return n, err
+}
+
+func (c *checksumReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.h.Write([]byte{b})
+	}
+	return b, err
+}
+
+// ReadSnapshot reads a snapshot of the active network from r, calling fn with every batch of rows of a table.
+// The batches are pointers to slices of pointers to rows. fn may be nil to only check the snapshot.
+// The checksum is verified after all rows have been read, so fn may have been called with corrupt rows
+// if an error is returned.
+func ReadSnapshot(r io.Reader, fn func(table string, rows interface{}) error) (header *SnapshotHeader, err error) {
+	magic := make([]byte, len(snapshotMagic))
+	if _, err = io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, []byte(snapshotMagic)) {
+		return nil, ErrSnapshotFormat
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	cr := &checksumReader{r: bufio.NewReader(gz), h: sha256.New()}
+	dec := gob.NewDecoder(cr)
+
+	header = &SnapshotHeader{}
+	if err = dec.Decode(header); err != nil {
+		return nil, err
+	}
+	if header.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", header.Version)
+	}
+	if header.Network != util.ActiveNet.Name {
+		return nil, fmt.Errorf("snapshot of %s, expected %s", header.Network, util.ActiveNet.Name)
+	}
+
+	rows := make(map[string]uint64)
+	for _, table := range header.Tables {
+		model := tables.NewModel(table)
+		if model == nil {
+			return nil, fmt.Errorf("unknown table %s", table)
+		}
+		sliceType := reflect.SliceOf(reflect.TypeOf(model))
+		for {
+			batch := reflect.New(sliceType)
+			if err = dec.Decode(batch.Interface()); err != nil {
+				return nil, fmt.Errorf("read %s: %w", table, err)
+			}
+			n := batch.Elem().Len()
+			if n == 0 {
+				break
+			}
+			rows[table] += uint64(n)
+			if fn == nil {
+				continue
+			}
+			if err = fn(table, batch.Interface()); err != nil {
+				return nil, fmt.Errorf("import %s: %w", table, err)
+			}
+		}
+	}
+
+	sum := cr.h.Sum(nil)
+	trailer := &snapshotTrailer{}
+	if err = dec.Decode(trailer); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sum, trailer.Checksum) {
+		return nil, ErrSnapshotChecksum
+	}
+	for _, table := range header.Tables {
+		if rows[table] != trailer.Rows[table] {
+			return nil, fmt.Errorf("snapshot table %s has %d rows, expected %d", table, rows[table], trailer.Rows[table])
+		}
+	}
+	return header, nil
+}
+
+// ImportSnapshot restores the tables of a snapshot read from r into an empty database.
+// Rows keep their primary keys, so the indexer continues from the block after the snapshot height.
+// The checksum is only verified after the rows are imported, so the snapshot should be checked
+// with ReadSnapshot first.
+func ImportSnapshot(db *dao.DB, r io.Reader) (*SnapshotHeader, error) {
+	empty, err := db.TablesEmpty(tables.Tables...)
+	if err != nil {
+		return nil, err
+	}
+	if !empty {
+		return nil, errors.New("database is not empty")
+	}
+	return ReadSnapshot(r, func(table string, rows interface{}) error {
+		return db.ImportRows(rows)
+	})
+}
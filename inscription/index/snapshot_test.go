@@ -0,0 +1,64 @@
+package index
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func snapshotTestRows(t *testing.T, db *dao.DB) map[string][]map[string]interface{} {
+	res := make(map[string][]map[string]interface{})
+	for _, table := range tables.Tables {
+		name := table.(interface{ TableName() string }).TableName()
+		rows := make([]map[string]interface{}, 0)
+		assert.NilError(t, db.Table(name).Order("id").Find(&rows).Error)
+		res[name] = rows
+	}
+	return res
+}
+
+func TestSnapshot(t *testing.T) {
+	db := newTestDB(t)
+	for height := uint32(0); height < 3; height++ {
+		header := &bytes.Buffer{}
+		assert.NilError(t, (&wire.BlockHeader{Nonce: height}).Serialize(header))
+		assert.NilError(t, db.SaveBlockInfo(&tables.BlockInfo{Height: height, Header: header.Bytes()}))
+		assert.NilError(t, db.CreateInscription(&tables.Inscriptions{
+			InscriptionId: tables.InscriptionId{TxId: fmt.Sprintf("%064d", height)},
+			SequenceNum:   int64(height),
+			Height:        height,
+			Body:          []byte(fmt.Sprintf("body %d", height)),
+		}))
+		assert.NilError(t, db.IncrementStatistic(height, tables.StatisticCommits, 1))
+	}
+	blockHash, err := db.BlockHash(2)
+	assert.NilError(t, err)
+
+	snapshot := &bytes.Buffer{}
+	header, err := ExportSnapshot(db, snapshot)
+	assert.NilError(t, err)
+	assert.Equal(t, header.Height, uint32(2))
+	assert.Equal(t, header.BlockHash, blockHash)
+
+	read, err := ReadSnapshot(bytes.NewReader(snapshot.Bytes()), nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, read, header)
+
+	imported := newTestDB(t)
+	_, err = ImportSnapshot(imported, bytes.NewReader(snapshot.Bytes()))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, snapshotTestRows(t, imported), snapshotTestRows(t, db))
+
+	// A second import must not overwrite the index.
+	_, err = ImportSnapshot(imported, bytes.NewReader(snapshot.Bytes()))
+	assert.ErrorContains(t, err, "not empty")
+
+	corrupt := bytes.Clone(snapshot.Bytes())
+	corrupt[len(corrupt)/2] ^= 0xff
+	_, err = ReadSnapshot(bytes.NewReader(corrupt), nil)
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,23 @@
+package tables
+
+import (
+	"time"
+)
+
+// Balance is the c-brc-20 balance of an address for a ticker id.
+// Total is the sum of Available and Transferable and is kept for ranking holders.
+type Balance struct {
+	Id           uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	TkId         string    `gorm:"column:tkid;type:varchar(255);uniqueIndex:uk_balances_tkid_address;index:idx_balances_tkid_total,priority:1;default:'';NOT NULL"`
+	Address      string    `gorm:"column:address;type:varchar(255);uniqueIndex:uk_balances_tkid_address;index;default:'';NOT NULL"`
+	Ticker       string    `gorm:"column:ticker;type:varchar(255);default:'';NOT NULL"`
+	Available    uint64    `gorm:"column:available;type:bigint unsigned;default:0;NOT NULL"`
+	Transferable uint64    `gorm:"column:transferable;type:bigint unsigned;default:0;NOT NULL"`
+	Total        uint64    `gorm:"column:total;type:bigint unsigned;index:idx_balances_tkid_total,priority:2;default:0;NOT NULL"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (b *Balance) TableName() string {
+	return "balances"
+}
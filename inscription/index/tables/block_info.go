@@ -8,10 +8,11 @@ import (
 
 type BlockInfo struct {
 	Id          uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
-	Height      uint32    `gorm:"column:height;type:int unsigned;index:idx_height;default:0;NOT NULL"`
-	SequenceNum int64     `gorm:"column:sequence_num;type:bigint;index:idx_sequence_num;default:0;NOT NULL"`
+	Height      uint32    `gorm:"column:height;type:int unsigned;index;default:0;NOT NULL"`
+	SequenceNum int64     `gorm:"column:sequence_num;type:bigint;index;default:0;NOT NULL"`
 	Header      []byte    `gorm:"column:header;type:blob;NOT NULL;comment:header"`
 	Timestamp   int64     `gorm:"column:timestamp;type:bigint;default:0;NOT NULL;comment:timestamp"`
+	LostSats    *uint64   `gorm:"column:lost_sats;type:bigint unsigned;comment:lost sats after the block, null if indexed by an older version"`
 	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 }
@@ -0,0 +1,19 @@
+package tables
+
+import (
+	"time"
+)
+
+// InscriptionParent is the relation between a child inscription and a parent inscription
+// that was spent as an input of the child's reveal transaction.
+type InscriptionParent struct {
+	Id                uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	SequenceNum       int64     `gorm:"column:sequence_num;type:bigint;index;default:0;NOT NULL"`        // child sequence number
+	ParentSequenceNum int64     `gorm:"column:parent_sequence_num;type:bigint;index;default:0;NOT NULL"` // parent sequence number
+	CreatedAt         time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (i *InscriptionParent) TableName() string {
+	return "inscription_parent"
+}
@@ -0,0 +1,24 @@
+package tables
+
+import (
+	"time"
+)
+
+// InscriptionTransfer is a location change of an inscription,
+// recorded when the inscription is created or moved by a transaction.
+type InscriptionTransfer struct {
+	Id          uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	SequenceNum int64     `gorm:"column:sequence_num;type:bigint;index;default:0;NOT NULL"`
+	TxId        string    `gorm:"column:tx_id;type:varchar(64);index;default:'';NOT NULL"`
+	Height      uint32    `gorm:"column:height;type:int unsigned;index;default:0;NOT NULL"`
+	OldSatPoint string    `gorm:"column:old_sat_point;type:varchar(255);default:'';NOT NULL"` // empty for the genesis location
+	NewSatPoint string    `gorm:"column:new_sat_point;type:varchar(255);default:'';NOT NULL"`
+	Owner       string    `gorm:"column:owner;type:varchar(255);index;default:'';NOT NULL"` // empty if spent as fee or lost
+	Timestamp   int64     `gorm:"column:timestamp;type:bigint;default:0;NOT NULL"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+}
+
+func (i *InscriptionTransfer) TableName() string {
+	return "inscription_transfer"
+}
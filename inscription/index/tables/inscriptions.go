@@ -1,9 +1,12 @@
 package tables
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/inscription-c/cins/constants"
 	"os"
@@ -17,22 +20,25 @@ type Inscriptions struct {
 	Id              uint64 `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"` // this is sequence_num
 	InscriptionId   `gorm:"embedded"`
 	Index           uint32          `gorm:"column:index;type:int unsigned;default:0;NOT NULL"` // outpoint index of tx
-	SequenceNum     int64           `gorm:"column:sequence_num;type:bigint;index:idx_sequence_num;default:0;NOT NULL"`
-	InscriptionNum  int64           `gorm:"column:inscription_num;type:bigint;index:idx_inscription_num;default:0;NOT NULL"`
-	Owner           string          `gorm:"column:owner;type:varchar(255);index:idx_owner;default:'';NOT NULL"`
-	Charms          uint16          `gorm:"column:charms;type:tinyint unsigned;default:0;NOT NULL"`
+	SequenceNum     int64           `gorm:"column:sequence_num;type:bigint;index;default:0;NOT NULL"`
+	InscriptionNum  int64           `gorm:"column:inscription_num;type:bigint;index;default:0;NOT NULL"`
+	Owner           string          `gorm:"column:owner;type:varchar(255);index;default:'';NOT NULL"`         // genesis owner
+	CurrentOwner    string          `gorm:"column:current_owner;type:varchar(255);index;default:'';NOT NULL"` // owner of the current location
+	Charms          uint16          `gorm:"column:charms;type:smallint unsigned;default:0;NOT NULL"`
 	Fee             uint64          `gorm:"column:fee;type:bigint unsigned;default:0;NOT NULL"`
 	Height          uint32          `gorm:"column:height;type:int unsigned;default:0;NOT NULL"`
-	Sat             uint64          `gorm:"column:sat;type:bigint unsigned;index:idx_sat;default:0;NOT NULL"`
+	Sat             uint64          `gorm:"column:sat;type:bigint unsigned;index;default:0;NOT NULL"`
 	Timestamp       int64           `gorm:"column:timestamp;type:bigint unsigned;default:0;NOT NULL"`
 	Body            []byte          `gorm:"column:body;type:mediumblob"`
 	ContentEncoding string          `gorm:"column:content_encoding;type:varchar(255);default:'';NOT NULL"`
 	ContentType     string          `gorm:"column:content_type;type:varchar(255);default:'';NOT NULL"`
-	MediaType       string          `gorm:"column:media_type;type:varchar(255);index:idx_media_type;default:'';NOT NULL"`
+	MediaType       string          `gorm:"column:media_type;type:varchar(255);index;default:'';NOT NULL"`
 	ContentSize     uint32          `gorm:"column:content_size;type:int unsigned;default:0;NOT NULL"`
 	ContentProtocol string          `gorm:"column:content_protocol;type:varchar(255);default:'';NOT NULL"`
+	Delegate        string          `gorm:"column:delegate;type:varchar(255);default:'';NOT NULL"` // inscription id whose content is served
 	CInsDescription CInsDescription `gorm:"embedded"`
 	Metadata        []byte          `gorm:"column:metadata;type:mediumblob"`
+	Metaprotocol    string          `gorm:"column:metaprotocol;type:varchar(255);index;default:'';NOT NULL"`
 	Pointer         int32           `gorm:"column:pointer;type:int;default:0;NOT NULL"`
 	CreatedAt       time.Time       `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 	UpdatedAt       time.Time       `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
@@ -43,8 +49,8 @@ func (i *Inscriptions) TableName() string {
 }
 
 type InscriptionId struct {
-	TxId   string `gorm:"column:tx_id;type:varchar(255);index:idx_tx_id;default:'';NOT NULL" json:"txid"` // tx id
-	Offset uint32 `gorm:"column:offset;type:int unsigned;default:0;NOT NULL" json:"offset"`               // inscription offset of tx
+	TxId   string `gorm:"column:tx_id;type:varchar(255);index;default:'';NOT NULL" json:"txid"` // tx id
+	Offset uint32 `gorm:"column:offset;type:int unsigned;default:0;NOT NULL" json:"offset"`     // inscription offset of tx
 }
 
 func (i *InscriptionId) MarshalJSON() ([]byte, error) {
@@ -58,8 +64,8 @@ func (i *InscriptionId) String() string {
 
 type CInsDescription struct {
 	Type     string `gorm:"column:type;type:varchar(255);default:'';NOT NULL" json:"type"` // blockchain/ordinals
-	Chain    string `gorm:"column:chain;type:varchar(255);index:idx_chain;default:'';NOT NULL" json:"chain"`
-	Contract string `gorm:"column:contract;type:varchar(255);index:idx_contract;default:'';NOT NULL" json:"contract"`
+	Chain    string `gorm:"column:chain;type:varchar(255);index;default:'';NOT NULL" json:"chain"`
+	Contract string `gorm:"column:contract;type:varchar(255);index;default:'';NOT NULL" json:"contract"`
 }
 
 func (u *CInsDescription) Data() []byte {
@@ -113,9 +119,39 @@ func StringToInscriptionId(s string) *InscriptionId {
 	}
 }
 
+// InscriptionIdFromBytes decodes an inscription id from its envelope field encoding,
+// the txid in internal byte order followed by the little endian offset with trailing zeros removed.
+// It returns nil if the data is not a valid inscription id.
+func InscriptionIdFromBytes(data []byte) *InscriptionId {
+	if len(data) < chainhash.HashSize || len(data) > chainhash.HashSize+4 {
+		return nil
+	}
+	txid, err := chainhash.NewHash(data[:chainhash.HashSize])
+	if err != nil {
+		return nil
+	}
+	offset := make([]byte, 4)
+	copy(offset, data[chainhash.HashSize:])
+	return &InscriptionId{
+		TxId:   txid.String(),
+		Offset: binary.LittleEndian.Uint32(offset),
+	}
+}
+
+// Bytes encodes the inscription id in the envelope field encoding.
+func (i *InscriptionId) Bytes() []byte {
+	txid, err := chainhash.NewHashFromStr(i.TxId)
+	if err != nil {
+		return nil
+	}
+	offset := make([]byte, 4)
+	binary.LittleEndian.PutUint32(offset, i.Offset)
+	return append(txid.CloneBytes(), bytes.TrimRight(offset, "\x00")...)
+}
+
 type Outpoint struct {
-	TxId  string `gorm:"column:tx_id;type:varchar(255);index:idx_tx_id;default:'';NOT NULL" json:"txid"` // tx id
-	Index uint32 `gorm:"column:index;type:int unsigned;default:0;NOT NULL"`                              // outpoint index of tx
+	TxId  string `gorm:"column:tx_id;type:varchar(255);index;default:'';NOT NULL" json:"txid"` // tx id
+	Index uint32 `gorm:"column:index;type:int unsigned;default:0;NOT NULL"`                    // outpoint index of tx
 }
 
 func (o *Outpoint) String() string {
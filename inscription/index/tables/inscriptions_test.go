@@ -0,0 +1,36 @@
+package tables
+
+import (
+	"testing"
+)
+
+func TestInscriptionIdBytes(t *testing.T) {
+	txid := "b61b0172d95e266c18aea0c624db987e971a5d6d4ebc2aaed85da4642d635735"
+	tests := []struct {
+		offset uint32
+		size   int
+	}{
+		{0, 32},
+		{1, 33},
+		{255, 33},
+		{256, 34},
+		{1 << 24, 36},
+	}
+	for _, tt := range tests {
+		id := NewInscriptionId(txid, tt.offset)
+		data := id.Bytes()
+		if len(data) != tt.size {
+			t.Fatalf("offset %d: got %d bytes, want %d", tt.offset, len(data), tt.size)
+		}
+		decoded := InscriptionIdFromBytes(data)
+		if decoded == nil || decoded.String() != id.String() {
+			t.Fatalf("offset %d: got %v, want %s", tt.offset, decoded, id)
+		}
+	}
+	if InscriptionIdFromBytes(make([]byte, 31)) != nil {
+		t.Fatal("expected nil for short data")
+	}
+	if InscriptionIdFromBytes(make([]byte, 37)) != nil {
+		t.Fatal("expected nil for long data")
+	}
+}
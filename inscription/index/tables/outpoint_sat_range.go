@@ -11,7 +11,7 @@ var ErrInvalidSatRange = errors.New("invalid sat range")
 
 type OutpointSatRange struct {
 	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
-	Outpoint  string    `gorm:"column:outpoint;type:varchar(255);index:idx_outpoint;default:;NOT NULL"`
+	Outpoint  string    `gorm:"column:outpoint;type:varchar(255);index;default:'';NOT NULL"`
 	SatRange  []byte    `gorm:"column:sat_range;type:longblob;default:;NOT NULL"`
 	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
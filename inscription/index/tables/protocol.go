@@ -8,25 +8,22 @@ type Protocol struct {
 	Id            uint64 `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"` // this is sequence_num
 	InscriptionId `gorm:"embedded"`
 	Index         uint32    `gorm:"column:index;type:int unsigned;default:0;NOT NULL"` // outpoint index of tx
-	SequenceNum   int64     `gorm:"column:sequence_num;type:bigint;index:idx_sequence_num;default:0;NOT NULL"`
-	Protocol      string    `gorm:"column:protocol;type:varchar(255);index:idx_protocol;default:;NOT NULL"`
-	Ticker        string    `gorm:"column:ticker;type:varchar(255);index:idx_ticker;default:;NOT NULL"`
-	Operator      string    `gorm:"column:operator;type:varchar(255);index:idx_operator;default:;NOT NULL"`
-	Owner         string    `gorm:"column:owner;type:varchar(255);index:idx_owner;default:;NOT NULL"`
+	SequenceNum   int64     `gorm:"column:sequence_num;type:bigint;index;default:0;NOT NULL"`
+	Protocol      string    `gorm:"column:protocol;type:varchar(255);index;default:'';NOT NULL"`
+	Ticker        string    `gorm:"column:ticker;type:varchar(255);index;default:'';NOT NULL"`
+	Operator      string    `gorm:"column:operator;type:varchar(255);index;default:'';NOT NULL"`
+	Owner         string    `gorm:"column:owner;type:varchar(255);index;default:'';NOT NULL"` // deployer, minter or sender
+	TkId          string    `gorm:"column:tkid;type:varchar(255);index;default:'';NOT NULL"`  // deploy inscription id of a mint or transfer
+	To            string    `gorm:"column:to;type:varchar(255);index;default:'';NOT NULL"`    // receiver of a transfer, empty until it is sent
 	Max           uint64    `gorm:"column:max;type:bigint unsigned;default:0;NOT NULL"`
 	Limit         uint64    `gorm:"column:limit;type:bigint unsigned;default:0;NOT NULL"`
 	Decimals      uint32    `gorm:"column:decimals;type:int unsigned;default:0;NOT NULL"`
+	Amount        uint64    `gorm:"column:amount;type:bigint unsigned;default:0;NOT NULL"`
+	Minted        uint64    `gorm:"column:minted;type:bigint unsigned;default:0;NOT NULL"` // total amount minted of a deploy
 	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 	UpdatedAt     time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 }
 
-// ProtocolAmount is a struct that represents the amount of a protocol.
-type ProtocolAmount struct {
-	TkID   string `gorm:"column:tkid" json:"ticker_id"`
-	Ticker string `gorm:"column:ticker" json:"ticker"`
-	Amount uint64 `gorm:"column:amount" json:"amount"`
-}
-
 func (t *Protocol) TableName() string {
 	return "protocol"
 }
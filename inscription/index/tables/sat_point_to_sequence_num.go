@@ -8,9 +8,9 @@ import (
 
 type SatPointToSequenceNum struct {
 	Id          uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
-	Outpoint    string    `gorm:"column:outpoint;type:varchar(255);index:idx_outpoint;default:;NOT NULL"`
+	Outpoint    string    `gorm:"column:outpoint;type:varchar(255);index;default:'';NOT NULL"`
 	Offset      uint64    `gorm:"column:offset;type:bigint unsigned;default:0;NOT NULL"`
-	SequenceNum int64     `gorm:"column:sequence_num;type:bigint;index:idx_sequence_num;default:0;NOT NULL"`
+	SequenceNum int64     `gorm:"column:sequence_num;type:bigint;index;default:0;NOT NULL"`
 	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 }
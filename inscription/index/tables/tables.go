@@ -1,8 +1,13 @@
 package tables
 
+import "reflect"
+
 var Tables = []interface{}{
+	&Balance{},
 	&BlockInfo{},
 	&Inscriptions{},
+	&InscriptionParent{},
+	&InscriptionTransfer{},
 	&OutpointSatRange{},
 	&OutpointValue{},
 	&Protocol{},
@@ -13,3 +18,21 @@ var Tables = []interface{}{
 	&SavePoint{},
 	&UndoLog{},
 }
+
+// tableTypes maps the table names to the model types of the Tables.
+var tableTypes = make(map[string]reflect.Type)
+
+func init() {
+	for _, table := range Tables {
+		tableTypes[table.(interface{ TableName() string }).TableName()] = reflect.TypeOf(table).Elem()
+	}
+}
+
+// NewModel returns a pointer to a new model of the table with the given name, nil if the table is unknown.
+func NewModel(table string) interface{} {
+	t, ok := tableTypes[table]
+	if !ok {
+		return nil
+	}
+	return reflect.New(t).Interface()
+}
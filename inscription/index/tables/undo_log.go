@@ -2,12 +2,26 @@ package tables
 
 import "time"
 
+// UndoOperation is the kind of change an undo log reverts.
+type UndoOperation string
+
+const (
+	UndoOperationInsert UndoOperation = "insert" // the row was inserted, rolled back by deleting it
+	UndoOperationUpdate UndoOperation = "update" // the row was updated, rolled back by restoring the before-image
+	UndoOperationDelete UndoOperation = "delete" // the row was deleted, rolled back by inserting the before-image
+)
+
+// UndoLog is a change to a row of an index table made while indexing a block.
+// The before-image is a JSON object of the changed columns, keyed by column name.
 type UndoLog struct {
-	Id        uint64    `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
-	Height    uint32    `gorm:"column:height;type:int(11) unsigned;index:idx_height;default:0;NOT NULL;comment:height of the block"`
-	Sql       string    `gorm:"column:sql;type:longtext;default:;NOT NULL;comment:sql statement"`
-	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
-	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	Id          uint64        `gorm:"column:id;primary_key;AUTO_INCREMENT;NOT NULL"`
+	Height      uint32        `gorm:"column:height;type:int unsigned;index;default:0;NOT NULL;comment:height of the block"`
+	Table       string        `gorm:"column:table_name;type:varchar(64);default:'';NOT NULL;comment:table of the changed row"`
+	Operation   UndoOperation `gorm:"column:operation;type:varchar(16);default:'';NOT NULL;comment:insert, update or delete"`
+	PrimaryKey  uint64        `gorm:"column:primary_key;type:bigint unsigned;default:0;NOT NULL;comment:id of the changed row"`
+	BeforeImage string        `gorm:"column:before_image;type:longtext;comment:columns of the row before the change"`
+	CreatedAt   time.Time     `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
+	UpdatedAt   time.Time     `gorm:"column:updated_at;type:timestamp;default:CURRENT_TIMESTAMP;NOT NULL"`
 }
 
 func (b *UndoLog) TableName() string {
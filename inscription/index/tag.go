@@ -121,3 +121,14 @@ func (t TagType) RemoveField(fields map[TagType][][]byte) []byte {
 		return res
 	}
 }
+
+// RemoveArray removes all values of a non-chunked field from a given map of fields.
+// It returns the removed values in the order they appeared.
+func (t TagType) RemoveArray(fields map[TagType][][]byte) [][]byte {
+	values, ok := fields[t]
+	if !ok {
+		return nil
+	}
+	delete(fields, t)
+	return values
+}
@@ -2,9 +2,11 @@ package index
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/gogf/gf/v2/util/gutil"
@@ -13,10 +15,10 @@ import (
 	"github.com/inscription-c/cins/inscription/index/model"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"github.com/inscription-c/cins/inscription/log"
-	"github.com/inscription-c/cins/pkg/signal"
 	"github.com/inscription-c/cins/pkg/util"
 	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
+	"slices"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -85,6 +87,9 @@ type OriginNew struct {
 	// Unbound is a boolean flag indicating whether the inscription is unbound.
 	Unbound bool
 
+	// Parents is a slice of the sequence numbers of the parents spent by the reveal transaction.
+	Parents []int64
+
 	// Inscription is a pointer to the Envelope struct that contains the inscription.
 	Inscription *Envelope
 }
@@ -100,7 +105,7 @@ type InscriptionUpdater struct {
 	idx *Indexer
 
 	// wtx is a pointer to the DB struct that is used for database operations.
-	wtx *dao.DB
+	wtx dao.Store
 
 	// flotsam is a slice of pointers to Flotsam structs. Each Flotsam represents a floating inscription.
 	flotsam []*Flotsam
@@ -287,6 +292,8 @@ func (u *InscriptionUpdater) indexEnvelopers(
 
 	inscribedOffsets := make(map[uint64]*inscribedOffsetEntity)
 
+	potentialParents := make(map[string]int64)
+
 	envelopes, err := util.NewPeekable(ParsedEnvelopFromTransaction(tx))
 	if err != nil {
 		return err
@@ -321,6 +328,7 @@ func (u *InscriptionUpdater) indexEnvelopers(
 				TxId:   v.TxId,
 				Offset: v.Inscriptions.Offset,
 			}
+			potentialParents[insId.String()] = v.Inscriptions.SequenceNum
 			floatingInscriptions = append(floatingInscriptions, &Flotsam{
 				InscriptionId: insId,
 				Offset:        offset,
@@ -348,8 +356,6 @@ func (u *InscriptionUpdater) indexEnvelopers(
 		} else {
 			if currentInputValue, ok = needDelOutpoints[preOutpoint]; !ok {
 				select {
-				case <-signal.InterruptChannel:
-					return signal.ErrInterrupted
 				case currentInputValue, ok = <-valueCh:
 					if !ok {
 						return errors.New("valueCh closed")
@@ -375,24 +381,8 @@ func (u *InscriptionUpdater) indexEnvelopers(
 				Offset: idCounter,
 			}
 
-			var curse Curse
-			if inscription.payload.UnRecognizedEvenField {
-				curse = CurseUnrecognizedEvenField
-			} else if inscription.payload.DuplicateField {
-				curse = CurseDuplicateField
-			} else if inscription.payload.IncompleteField {
-				curse = CurseIncompleteField
-			} else if inscription.index != 0 {
-				curse = CurseNotInFirstInput
-			} else if inscription.offset != 0 {
-				curse = CurseNotAtOffsetZero
-			} else if len(inscription.payload.Pointer) > 0 {
-				curse = CursePointer
-			} else if inscription.pushNum {
-				curse = CursePushNum
-			} else if inscription.stutter {
-				curse = CurseStutter
-			} else {
+			curse := inscription.Curse()
+			if curse == 0 {
 				offsetEntity, ok := inscribedOffsets[offset]
 				if ok {
 					if offsetEntity.count > 1 {
@@ -458,7 +448,20 @@ func (u *InscriptionUpdater) indexEnvelopers(
 	}
 
 	// TODO index transaction
-	// TODO potential_parents
+
+	// An inscription can only claim the parents that are spent as inputs of its reveal transaction.
+	for _, flotsam := range floatingInscriptions {
+		if flotsam.Origin.New == nil {
+			continue
+		}
+		for _, parent := range flotsam.Origin.New.Inscription.payload.Parents {
+			sequenceNum, ok := potentialParents[parent.String()]
+			if !ok || slices.Contains(flotsam.Origin.New.Parents, sequenceNum) {
+				continue
+			}
+			flotsam.Origin.New.Parents = append(flotsam.Origin.New.Parents, sequenceNum)
+		}
+	}
 
 	// still have to normalize over inscription size
 	for _, flotsam := range floatingInscriptions {
@@ -562,7 +565,7 @@ func (u *InscriptionUpdater) indexEnvelopers(
 		}
 
 		// Update the location of the inscription in the database.
-		if err := u.updateInscriptionLocation(inputSatRange, flotsam, newSatpoint); err != nil {
+		if err := u.updateInscriptionLocation(tx, inputSatRange, flotsam, newSatpoint); err != nil {
 			return err
 		}
 	}
@@ -576,7 +579,7 @@ func (u *InscriptionUpdater) indexEnvelopers(
 			newSatPoint := &tables.SatPointToSequenceNum{
 				Offset: *u.lostSats + flotsam.Offset - outputValue,
 			}
-			if err := u.updateInscriptionLocation(inputSatRange, flotsam, newSatPoint); err != nil {
+			if err := u.updateInscriptionLocation(tx, inputSatRange, flotsam, newSatPoint); err != nil {
 				return err
 			}
 			return nil
@@ -607,6 +610,7 @@ func (u *InscriptionUpdater) indexEnvelopers(
 
 // updateInscriptionLocation updates the location of an inscription.
 func (u *InscriptionUpdater) updateInscriptionLocation(
+	tx *wire.MsgTx,
 	inputSatRanges tables.SatRanges,
 	flotsam *Flotsam,
 	newSatPoint *tables.SatPointToSequenceNum,
@@ -627,6 +631,15 @@ func (u *InscriptionUpdater) updateInscriptionLocation(
 			return err
 		}
 		sequenceNumber = inscription.SequenceNum
+		owner := satPointOwner(tx, newSatPoint)
+
+		// Settle the protocol operation carried by the inscription, such as a c-brc-20 transfer.
+		if err := NewProtocol(u.wtx, &inscription).SendProtocol(u.idx.height, owner); err != nil {
+			return err
+		}
+		if err := u.wtx.UpdateInscriptionOwner(u.idx.height, sequenceNumber, owner); err != nil {
+			return err
+		}
 	} else if flotsam.Origin.New != nil { // If the origin of the flotsam is new, process it.
 		unbound = flotsam.Origin.New.Unbound
 		inscriptionNumber := int64(0)
@@ -672,25 +685,7 @@ func (u *InscriptionUpdater) updateInscriptionLocation(
 
 		// If the Sat is not nil, set the appropriate charms based on its properties.
 		if sat != nil {
-			if sat.NineBall() {
-				CharmNineBall.Set(&charms)
-			}
-			if sat.Coin() {
-				CharmCoin.Set(&charms)
-			}
-
-			// Set the charm based on the rarity of the Sat.
-			switch sat.Rarity() {
-			case RarityCommon, RarityMythic:
-			case RarityUncommon:
-				CharmUncommon.Set(&charms)
-			case RarityRare:
-				CharmRare.Set(&charms)
-			case RarityEpic:
-				CharmEpic.Set(&charms)
-			case RarityLegendary:
-				CharmLegendary.Set(&charms)
-			}
+			charms |= sat.Charms()
 		}
 
 		// If the new newSatPoint is empty, set the lost charm.
@@ -722,6 +717,7 @@ func (u *InscriptionUpdater) updateInscriptionLocation(
 			SequenceNum:     sequenceNumber,
 			InscriptionNum:  inscriptionNumber,
 			Owner:           inscription.owner,
+			CurrentOwner:    satPointOwner(tx, newSatPoint),
 			CInsDescription: inscription.payload.CInsDescription,
 			Charms:          charms,
 			Fee:             uint64(flotsam.Origin.New.Fee),
@@ -733,8 +729,12 @@ func (u *InscriptionUpdater) updateInscriptionLocation(
 			MediaType:       string(inscription.payload.ContentType.MediaType()),
 			ContentSize:     uint32(len(inscription.payload.Body)),
 			Metadata:        inscription.payload.Metadata,
+			Metaprotocol:    inscription.payload.Metaprotocol,
 			Pointer:         gconv.Int32(string(inscription.payload.Pointer)),
 		}
+		if inscription.payload.Delegate != nil {
+			entry.Delegate = inscription.payload.Delegate.String()
+		}
 		// If the Sat is not nil, set the Sat and offset in the entry.
 		if sat != nil {
 			entry.Sat = uint64(*sat)
@@ -754,6 +754,12 @@ func (u *InscriptionUpdater) updateInscriptionLocation(
 		if err := NewProtocol(u.wtx, entry).SaveProtocol(); err != nil && !errors.Is(err, util.NotSupportedProtocol) {
 			return err
 		}
+		// Create parent relations
+		for _, parent := range flotsam.Origin.New.Parents {
+			if err := u.wtx.CreateInscriptionParent(u.idx.height, sequenceNumber, parent); err != nil {
+				return err
+			}
+		}
 	}
 
 	satPoint := newSatPoint
@@ -768,7 +774,42 @@ func (u *InscriptionUpdater) updateInscriptionLocation(
 	if err := u.wtx.SetSatPointToSequenceNum(u.idx.height, satPoint); err != nil {
 		return err
 	}
-	return nil
+
+	// Record the location change in the inscription transfer history.
+	transfer := &tables.InscriptionTransfer{
+		SequenceNum: sequenceNumber,
+		TxId:        tx.TxHash().String(),
+		Height:      u.idx.height,
+		NewSatPoint: satPoint.String(),
+		Owner:       satPointOwner(tx, satPoint),
+		Timestamp:   u.timestamp,
+	}
+	if satPoint.Outpoint == "" {
+		transfer.NewSatPoint = tables.FormatSatPoint(wire.OutPoint{}.String(), satPoint.Offset)
+	}
+	if flotsam.Origin.Old != nil {
+		transfer.OldSatPoint = flotsam.Origin.Old.OldSatPoint.String()
+	}
+	return u.wtx.CreateInscriptionTransfer(u.idx.height, transfer)
+}
+
+// satPointOwner returns the address of the transaction output that a satpoint is located in.
+// Outputs without an address are identified by their hex encoded script.
+// It returns an empty string if the satpoint is spent as fee or lost.
+func satPointOwner(tx *wire.MsgTx, satPoint *tables.SatPointToSequenceNum) string {
+	if blockchain.IsCoinBaseTx(tx) {
+		return ""
+	}
+	outpoint, err := wire.NewOutPointFromString(satPoint.Outpoint)
+	if err != nil || int(outpoint.Index) >= len(tx.TxOut) {
+		return ""
+	}
+	pkScript := tx.TxOut[outpoint.Index].PkScript
+	_, addresses, _, err := txscript.ExtractPkScriptAddrs(pkScript, util.ActiveNet.Params)
+	if err != nil || len(addresses) == 0 {
+		return hex.EncodeToString(pkScript)
+	}
+	return addresses[0].String()
 }
 
 // calculateSat calculates the Sat of an inscription.
@@ -827,40 +868,31 @@ func (u *InscriptionUpdater) fetchOutputValues(tx *wire.MsgTx, maxCurrentNum int
 
 	errWg := &errgroup.Group{}
 	for inputIndex := range tx.TxIn {
-		select {
-		case <-signal.InterruptChannel:
-			err = signal.ErrInterrupted
-			return
-		default:
-			txIn := tx.TxIn[inputIndex]
-
-			// If the input is a coinbase, skip it.
-			if util.IsNullOutpoint(txIn.PreviousOutPoint) {
-				continue
-			}
+		txIn := tx.TxIn[inputIndex]
 
-			// If the value of the input is already cached, skip it.
-			if _, ok := u.valueCache.Read(txIn.PreviousOutPoint.String()); ok {
-				continue
-			}
+		// If the input is a coinbase, skip it.
+		if util.IsNullOutpoint(txIn.PreviousOutPoint) {
+			continue
+		}
 
-			errWg.Go(func() error {
-				if signal.InterruptRequested() {
-					return nil
-				}
-				// Try to get the value of the input from the database.
-				value, err := u.idx.DB().GetValueByOutpoint(txIn.PreviousOutPoint.String())
-				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-					return err
-				}
-				if err == nil {
-					needDelOutpointsLock.Lock()
-					needDelOutpoints[txIn.PreviousOutPoint.String()] = value
-					needDelOutpointsLock.Unlock()
-				}
-				return nil
-			})
+		// If the value of the input is already cached, skip it.
+		if _, ok := u.valueCache.Read(txIn.PreviousOutPoint.String()); ok {
+			continue
 		}
+
+		errWg.Go(func() error {
+			// Try to get the value of the input from the database.
+			value, err := u.idx.DB().GetValueByOutpoint(txIn.PreviousOutPoint.String())
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil {
+				needDelOutpointsLock.Lock()
+				needDelOutpoints[txIn.PreviousOutPoint.String()] = value
+				needDelOutpointsLock.Unlock()
+			}
+			return nil
+		})
 	}
 
 	if err = errWg.Wait(); err != nil {
@@ -871,27 +903,21 @@ func (u *InscriptionUpdater) fetchOutputValues(tx *wire.MsgTx, maxCurrentNum int
 		defer close(needFetchOutpointsCh)
 		// Loop over each input in the transaction.
 		for inputIndex := range tx.TxIn {
-			select {
-			case <-signal.InterruptChannel:
-				err = signal.ErrInterrupted
-				return
-			default:
-				txIn := tx.TxIn[inputIndex]
+			txIn := tx.TxIn[inputIndex]
 
-				// If the input is a coinbase, skip it.
-				if util.IsNullOutpoint(txIn.PreviousOutPoint) {
-					continue
-				}
+			// If the input is a coinbase, skip it.
+			if util.IsNullOutpoint(txIn.PreviousOutPoint) {
+				continue
+			}
 
-				// If the value of the input is already cached, skip it.
-				if _, ok := u.valueCache.Read(txIn.PreviousOutPoint.String()); ok {
-					continue
-				}
+			// If the value of the input is already cached, skip it.
+			if _, ok := u.valueCache.Read(txIn.PreviousOutPoint.String()); ok {
+				continue
+			}
 
-				// Try to get the value of the input from the database.
-				if _, ok := needDelOutpoints[txIn.PreviousOutPoint.String()]; !ok {
-					needFetchOutpointsCh <- &txIn.PreviousOutPoint
-				}
+			// Try to get the value of the input from the database.
+			if _, ok := needDelOutpoints[txIn.PreviousOutPoint.String()]; !ok {
+				needFetchOutpointsCh <- &txIn.PreviousOutPoint
 			}
 		}
 	}()
@@ -910,36 +936,31 @@ func (u *InscriptionUpdater) fetchOutputValues(tx *wire.MsgTx, maxCurrentNum int
 		}()
 
 		for outpoint := range needFetchOutpointsCh {
-			select {
-			case <-signal.InterruptChannel:
-				return
-			default:
-				i := fetchOutpointsNum % currentNum
-				needFetchOutpoints[i] = outpoint
-				res := u.idx.BatchRpcClient().GetRawTransactionAsync(&outpoint.Hash)
-				batchResult[i] = &res
-
-				if (fetchOutpointsNum+1)%currentNum == 0 {
-					if err := u.idx.BatchRpcClient().Send(); err != nil {
+			i := fetchOutpointsNum % currentNum
+			needFetchOutpoints[i] = outpoint
+			res := u.idx.BatchRpcClient().GetRawTransactionAsync(&outpoint.Hash)
+			batchResult[i] = &res
+
+			if (fetchOutpointsNum+1)%currentNum == 0 {
+				if err := u.idx.BatchRpcClient().Send(); err != nil {
+					errCh <- err
+					close(errCh)
+					return
+				}
+				// Loop over the results of the batch.
+				for ii := 0; ii < currentNum; ii++ {
+					// Receive the result of the fetch operation.
+					tx, err := batchResult[ii].Receive()
+					if err != nil {
 						errCh <- err
 						close(errCh)
 						return
 					}
-					// Loop over the results of the batch.
-					for ii := 0; ii < currentNum; ii++ {
-						// Receive the result of the fetch operation.
-						tx, err := batchResult[ii].Receive()
-						if err != nil {
-							errCh <- err
-							close(errCh)
-							return
-						}
-						// Send the value of the output to the value channel.
-						valueCh <- tx.MsgTx().TxOut[needFetchOutpoints[ii].Index].Value
-						batchResult[ii] = nil
-					}
-					commitNum++
+					// Send the value of the output to the value channel.
+					valueCh <- tx.MsgTx().TxOut[needFetchOutpoints[ii].Index].Value
+					batchResult[ii] = nil
 				}
+				commitNum++
 			}
 			fetchOutpointsNum++
 		}
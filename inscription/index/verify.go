@@ -0,0 +1,274 @@
+// Package index provides the consistency verification of the index.
+package index
+
+import (
+	"fmt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Checks run by Verify.
+const (
+	VerifyBlockChain  = "block_chain"  // block_info hashes chain together and match the node
+	VerifySatPoint    = "sat_point"    // sat_point_to_sequence_num rows point to unspent outputs
+	VerifySequenceNum = "sequence_num" // inscription sequence numbers are contiguous
+	VerifyStatistic   = "statistic"    // statistic counters match the inscriptions
+)
+
+// VerifyChecks are all checks run by Verify.
+var VerifyChecks = []string{VerifyBlockChain, VerifySatPoint, VerifySequenceNum, VerifyStatistic}
+
+const (
+	// verifyRpcBatch is the number of rows checked against the node at once.
+	verifyRpcBatch = 100
+	// verifyBatchSize is the number of rows read at once by the database checks.
+	verifyBatchSize = 10_000
+)
+
+// VerifyProblem is an inconsistency found by Verify.
+type VerifyProblem struct {
+	Check   string `json:"check"`
+	Key     string `json:"key"` // height, outpoint, sequence number or statistic name of the problem
+	Message string `json:"message"`
+}
+
+// VerifyReport is the result of Verify.
+type VerifyReport struct {
+	Height     uint32            `json:"height"` // height of the last indexed block
+	NodeHeight uint32            `json:"node_height"`
+	Checks     []string          `json:"checks"`
+	Skipped    map[string]string `json:"skipped"` // reasons of the checks that were not run
+	Problems   []*VerifyProblem  `json:"problems"`
+}
+
+// verifier runs the checks of Verify.
+type verifier struct {
+	db     dao.Store
+	cli    *rpcclient.Client
+	report *VerifyReport
+}
+
+// Verify checks the index for internal consistency and against the node.
+// The checks are VerifyChecks if none are given. The index is read in one transaction,
+// so a running indexer does not cause problems, but blocks indexed after the node
+// tip changed may be reported.
+func Verify(db *dao.DB, cli *rpcclient.Client, checks ...string) (*VerifyReport, error) {
+	if len(checks) == 0 {
+		checks = VerifyChecks
+	}
+	for _, check := range checks {
+		if !slices.Contains(VerifyChecks, check) {
+			return nil, fmt.Errorf("unknown check %s", check)
+		}
+	}
+	nodeHeight, err := cli.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+	report := &VerifyReport{
+		NodeHeight: uint32(nodeHeight),
+		Checks:     checks,
+		Skipped:    make(map[string]string),
+		Problems:   make([]*VerifyProblem, 0),
+	}
+
+	err = db.Transaction(func(tx *dao.DB) error {
+		v := &verifier{db: tx, cli: cli, report: report}
+		height, err := tx.BlockHeight()
+		if err != nil {
+			return err
+		}
+		report.Height = height
+
+		for _, check := range checks {
+			switch check {
+			case VerifyBlockChain:
+				err = v.verifyBlockChain()
+			case VerifySatPoint:
+				err = v.verifySatPoint()
+			case VerifySequenceNum:
+				err = v.verifySequenceNum()
+			case VerifyStatistic:
+				err = v.verifyStatistic()
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", check, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (v *verifier) problem(check, key, format string, args ...interface{}) {
+	v.report.Problems = append(v.report.Problems, &VerifyProblem{
+		Check:   check,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// verifyBlockChain checks that every block follows the previous one and has the hash of the node at its height.
+func (v *verifier) verifyBlockChain() error {
+	var prev *tables.BlockInfo
+	var prevHash chainhash.Hash
+	return v.db.ExportTable(&tables.BlockInfo{}, verifyRpcBatch, func(rows interface{}) error {
+		list := *rows.(*[]*tables.BlockInfo)
+		hashes := make([]chainhash.Hash, len(list))
+		futures := make([]rpcclient.FutureGetBlockHashResult, len(list))
+		for i, info := range list {
+			if info.Height <= v.report.NodeHeight {
+				futures[i] = v.cli.GetBlockHashAsync(int64(info.Height))
+			}
+		}
+
+		for i, info := range list {
+			key := strconv.FormatUint(uint64(info.Height), 10)
+			header, err := info.LoadHeader()
+			if err != nil {
+				v.problem(VerifyBlockChain, key, "invalid block header: %v", err)
+				prev = nil
+				continue
+			}
+			hashes[i] = header.BlockHash()
+			if prev != nil {
+				if info.Height != prev.Height+1 {
+					v.problem(VerifyBlockChain, key, "block follows block %d", prev.Height)
+				} else if header.PrevBlock != prevHash {
+					v.problem(VerifyBlockChain, key, "previous block %s, expected %s", header.PrevBlock, prevHash)
+				}
+			}
+			prev, prevHash = info, hashes[i]
+
+			if futures[i] == nil {
+				v.problem(VerifyBlockChain, key, "block %s is above the node tip", hashes[i])
+				continue
+			}
+			nodeHash, err := futures[i].Receive()
+			if err != nil {
+				return err
+			}
+			if *nodeHash != hashes[i] {
+				v.problem(VerifyBlockChain, key, "block %s, node has %s", hashes[i], nodeHash)
+			}
+		}
+		return nil
+	})
+}
+
+// verifySatPoint checks that the outputs holding inscriptions are unspent.
+// Outputs spent in blocks after the index height cannot be told apart, so the check requires an index at the node tip.
+func (v *verifier) verifySatPoint() error {
+	if v.report.Height != v.report.NodeHeight {
+		v.report.Skipped[VerifySatPoint] = fmt.Sprintf("index at height %d is not at the node tip %d", v.report.Height, v.report.NodeHeight)
+		return nil
+	}
+	after := ""
+	for {
+		outpoints, err := v.db.SatPointOutpoints(after, verifyRpcBatch)
+		if err != nil {
+			return err
+		}
+		if len(outpoints) == 0 {
+			return nil
+		}
+		after = outpoints[len(outpoints)-1]
+
+		futures := make([]rpcclient.FutureGetTxOutResult, len(outpoints))
+		for i, outpoint := range outpoints {
+			op, err := wire.NewOutPointFromString(outpoint)
+			if err != nil {
+				v.problem(VerifySatPoint, outpoint, "invalid outpoint: %v", err)
+				continue
+			}
+			// Lost and unbound inscriptions are kept at outpoints without a transaction.
+			if op.Hash == (chainhash.Hash{}) {
+				continue
+			}
+			futures[i] = v.cli.GetTxOutAsync(&op.Hash, op.Index, false)
+		}
+		for i, future := range futures {
+			if future == nil {
+				continue
+			}
+			txOut, err := future.Receive()
+			if err != nil {
+				return err
+			}
+			if txOut == nil {
+				v.problem(VerifySatPoint, outpoints[i], "output is spent or does not exist")
+			}
+		}
+	}
+}
+
+// verifySequenceNum checks that the sequence numbers of inscriptions are unique and contiguous from 1.
+func (v *verifier) verifySequenceNum() error {
+	duplicates, err := v.db.DuplicateSequenceNums()
+	if err != nil {
+		return err
+	}
+	for _, n := range duplicates {
+		v.problem(VerifySequenceNum, strconv.FormatInt(n, 10), "sequence number is used by more than one inscription")
+	}
+
+	prev := int64(0)
+	for {
+		list, err := v.db.SequenceNums(prev, verifyBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			break
+		}
+		for _, n := range list {
+			if n > prev+1 {
+				v.problem(VerifySequenceNum, strconv.FormatInt(prev+1, 10), "sequence numbers %d to %d are missing", prev+1, n-1)
+			}
+			prev = n
+		}
+	}
+
+	invalid, err := v.db.SequenceNums(math.MinInt64, 1)
+	if err != nil {
+		return err
+	}
+	if len(invalid) > 0 && invalid[0] <= 0 {
+		v.problem(VerifySequenceNum, strconv.FormatInt(invalid[0], 10), "sequence numbers start at 1")
+	}
+	return nil
+}
+
+// verifyStatistic checks the inscription counters against the inscriptions, counted as a rollback restores them.
+func (v *verifier) verifyStatistic() error {
+	counts, err := v.db.CountInscriptions(CharmUnbound.Flag())
+	if err != nil {
+		return err
+	}
+	for _, counter := range []struct {
+		name  tables.StatisticType
+		count uint64
+	}{
+		{tables.StatisticBlessedInscriptions, counts.Blessed},
+		{tables.StatisticCursedInscriptions, counts.Cursed},
+		{tables.StatisticUnboundInscriptions, counts.Unbound},
+	} {
+		stat, err := v.db.GetStatisticCountByName(counter.name)
+		if err != nil {
+			return err
+		}
+		if stat != counter.count {
+			v.problem(VerifyStatistic, string(counter.name), "counter is %d, %d inscriptions", stat, counter.count)
+		}
+	}
+	return nil
+}
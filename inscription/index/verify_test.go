@@ -0,0 +1,33 @@
+package index
+
+import (
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestVerifyInscriptions(t *testing.T) {
+	db := newTestDB(t)
+	// a mock inscription, with a negative sequence number, is not counted
+	for _, n := range []int64{-1, 1, 2, 4, 4, 7} {
+		assert.NilError(t, db.CreateInscription(&tables.Inscriptions{
+			InscriptionId:  tables.InscriptionId{TxId: fmt.Sprintf("%064d", n)},
+			SequenceNum:    n,
+			InscriptionNum: 1 - n,
+		}))
+	}
+	assert.NilError(t, db.SetStatistic(0, tables.StatisticBlessedInscriptions, 1))
+	assert.NilError(t, db.SetStatistic(0, tables.StatisticCursedInscriptions, 2))
+
+	v := &verifier{db: db, report: &VerifyReport{}}
+	assert.NilError(t, v.verifySequenceNum())
+	assert.NilError(t, v.verifyStatistic())
+	assert.DeepEqual(t, v.report.Problems, []*VerifyProblem{
+		{Check: VerifySequenceNum, Key: "4", Message: "sequence number is used by more than one inscription"},
+		{Check: VerifySequenceNum, Key: "3", Message: "sequence numbers 3 to 3 are missing"},
+		{Check: VerifySequenceNum, Key: "5", Message: "sequence numbers 5 to 6 are missing"},
+		{Check: VerifySequenceNum, Key: "-1", Message: "sequence numbers start at 1"},
+		{Check: VerifyStatistic, Key: "CursedInscriptions", Message: "counter is 2, 4 inscriptions"},
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/netparams"
 	"github.com/inscription-c/cins/btcd/rpcclient"
 	"github.com/inscription-c/cins/constants"
@@ -40,18 +42,25 @@ var (
 	destination          string
 	cInsDescriptionFile  string
 	noBackup             bool
+	delegate             string
+	batchFilePath        string
+	feeRate              float64
+	targetBlocks         int64
+	utxo                 []string
+	minConf              int
+	psbtMode             bool
 )
 
 // InsufficientBalanceError is an error that represents an insufficient balance.
 var InsufficientBalanceError = errors.New("InsufficientBalanceError")
 
 func init() {
-	Cmd.Flags().StringVarP(&indexerUrl, "indexer_url", "", DefaultMainNetIndexerUrl, "the URL of indexer server (default http://localhost:8335, testnet: http://localhost:18335)")
-	Cmd.Flags().StringVarP(&walletUrl, "wallet_url", "", "localhost:8332", "the URL of wallet RPC server to connect to (default http://localhost:8332, testnet: localhost:18332)")
-	Cmd.Flags().StringVarP(&walletRpcUser, "wallet_rpc_user", "", "root", "wallet rpc server user")
-	Cmd.Flags().StringVarP(&walletRpcPass, "wallet_rpc_pass", "", "root", "wallet rpc server password")
-	Cmd.Flags().StringVarP(&walletPass, "wallet_pass", "", "root", "wallet password for master private key")
-	Cmd.Flags().BoolVarP(&testnet, "testnet", "t", false, "bitcoin testnet3")
+	Cmd.PersistentFlags().StringVarP(&indexerUrl, "indexer_url", "", DefaultMainNetIndexerUrl, "the URL of indexer server (default http://localhost:8335, testnet: http://localhost:18335)")
+	Cmd.PersistentFlags().StringVarP(&walletUrl, "wallet_url", "", "localhost:8332", "the URL of wallet RPC server to connect to (default http://localhost:8332, testnet: localhost:18332)")
+	Cmd.PersistentFlags().StringVarP(&walletRpcUser, "wallet_rpc_user", "", "root", "wallet rpc server user")
+	Cmd.PersistentFlags().StringVarP(&walletRpcPass, "wallet_rpc_pass", "", "root", "wallet rpc server password")
+	Cmd.PersistentFlags().StringVarP(&walletPass, "wallet_pass", "", "root", "wallet password for master private key")
+	Cmd.PersistentFlags().BoolVarP(&testnet, "testnet", "t", false, "bitcoin testnet3")
 	Cmd.Flags().StringVarP(&inscriptionsFilePath, "filepath", "f", "", "inscription file path")
 	Cmd.Flags().StringVarP(&cInsDescriptionFile, "c_ins_description", "", "", "cins protocol description.")
 	Cmd.Flags().StringVarP(&destination, "dest", "", "", "Send inscription to <DESTINATION> address.")
@@ -61,22 +70,19 @@ func init() {
 	Cmd.Flags().StringVarP(&jsonMetadata, "json_metadata", "", "", "Include JSON in file at <METADATA> converted to CBOR as inscription metadata")
 	Cmd.Flags().BoolVarP(&dryRun, "dry_run", "", false, "Don't sign or broadcast transactions.")
 	Cmd.Flags().BoolVarP(&cbrc20, "c_brc_20", "", false, "is c-brc-20 protocol, add this flag will auto check protocol content effectiveness")
-	Cmd.Flags().BoolVarP(&noBackup, "no_backup", "", false, "Do not back up recovery key.")
-	if err := Cmd.MarkFlagRequired("filepath"); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	if err := Cmd.MarkFlagRequired("dest"); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	if err := Cmd.MarkFlagRequired("c_ins_description"); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
+	Cmd.Flags().BoolVarP(&noBackup, "no_backup", "", false, "Do not back up recovery key, the key is left out of the session once the reveal transactions are signed.")
+	Cmd.Flags().StringVarP(&delegate, "delegate", "", "", "Delegate inscription content to <DELEGATE> inscription id, the file path can be omitted.")
+	Cmd.PersistentFlags().Float64VarP(&feeRate, "fee_rate", "", 0, "Fee rate of the commit and reveal transactions in sat/vB, estimated by the wallet node if zero.")
+	Cmd.PersistentFlags().Int64VarP(&targetBlocks, "target_blocks", "", DefaultTargetBlocks, "Confirmation target in blocks of the fee rate estimation.")
+	Cmd.Flags().StringSliceVarP(&utxo, "utxo", "", []string{}, "Spend the UTXO <TXID:VOUT> in the commit transaction, repeatable.")
+	Cmd.Flags().IntVarP(&minConf, "min_conf", "", 1, "Minimum confirmations of the UTXOs spent by the commit transaction.")
+	Cmd.Flags().BoolVarP(&psbtMode, "psbt", "", false, "Print the unsigned commit transaction as a PSBT for an external signer, sign the reveal with the finalize command.")
+	Cmd.Flags().StringVarP(&batchFilePath, "batch", "", "", "Inscribe the inscriptions of the YAML or JSON manifest at <BATCH> from one commit transaction.")
 }
 
-func configCheck() error {
+// initConfig is a function that applies the network flags and initializes the log,
+// for the inscribe command and its subcommands.
+func initConfig() error {
 	if testnet {
 		walletUrl = "http://localhost:18332"
 		if indexerUrl == DefaultMainNetIndexerUrl {
@@ -85,17 +91,62 @@ func configCheck() error {
 		util.ActiveNet = &netparams.TestNet3Params
 	}
 
+	if feeRate < 0 {
+		return errors.New("fee_rate must be greater than or equal 0")
+	}
+	if targetBlocks <= 0 {
+		return errors.New("target_blocks must be greater than 0")
+	}
+
+	// Initialize log rotation.  After log rotation has been initialized, the
+	// logger variables may be used.
+	logFile := btcutil.AppDataDir(filepath.Join(constants.AppName, "inscription", "logs", "inscription.log"), false)
+	log.InitLogRotator(logFile)
+	return nil
+}
+
+func configCheck() error {
+	if err := initConfig(); err != nil {
+		return err
+	}
+
 	//if postage < constants.DustLimit {
 	//	return fmt.Errorf("postage must be greater than or equal %d", constants.DustLimit)
 	//}
 	if postage > constants.MaxPostage {
 		return fmt.Errorf("postage must be less than or equal %d", constants.MaxPostage)
 	}
+	if minConf < 0 {
+		return errors.New("min_conf must be greater than or equal 0")
+	}
+	for _, v := range utxo {
+		if _, err := wire.NewOutPointFromString(v); err != nil {
+			return fmt.Errorf("invalid utxo %s: %w", v, err)
+		}
+	}
 
-	// Initialize log rotation.  After log rotation has been initialized, the
-	// logger variables may be used.
-	logFile := btcutil.AppDataDir(filepath.Join(constants.AppName, "inscription", "logs", "inscription.log"), false)
-	log.InitLogRotator(logFile)
+	// the inscriptions of a batch are checked when the manifest is read
+	if batchFilePath != "" {
+		if cInsDescriptionFile != "" {
+			if _, err := tables.CInsDescriptionFromFile(cInsDescriptionFile); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if destination == "" {
+		return errors.New("dest is required")
+	}
+	if cInsDescriptionFile == "" {
+		return errors.New("c_ins_description is required")
+	}
+	if inscriptionsFilePath == "" && delegate == "" {
+		return errors.New("either filepath or delegate is required")
+	}
+	if delegate != "" && tables.StringToInscriptionId(delegate) == nil {
+		return errors.New("invalid delegate inscription id")
+	}
 
 	// unlock condition check
 	if _, err := tables.CInsDescriptionFromFile(cInsDescriptionFile); err != nil {
@@ -129,42 +180,57 @@ func inscribe() error {
 	}
 
 	// Create a new wallet client
-	walletCli, err := rpcclient.NewClient(
-		rpcclient.WithClientHost(walletUrl),
-		rpcclient.WithClientUser(walletRpcUser),
-		rpcclient.WithClientPassword(walletRpcPass),
-	)
+	walletCli, err := newWalletClient()
 	if err != nil {
 		return err
 	}
-	signal.AddInterruptHandler(func() {
-		walletCli.Shutdown()
-	})
 
 	// Get the unlock condition from the file path
-	cInsDescription, err := tables.CInsDescriptionFromFile(cInsDescriptionFile)
-	if err != nil {
-		return err
+	var cInsDescription *tables.CInsDescription
+	if cInsDescriptionFile != "" {
+		cInsDescription, err = tables.CInsDescriptionFromFile(cInsDescriptionFile)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Create a new inscription from the file path
-	inscription, err := NewFromPath(inscriptionsFilePath,
+	// Create a new inscription from the file path, a delegate inscription has no body of its own
+	opts := []Option{
 		WithIndexer(indexer.NewIndexer(indexerUrl)),
 		WithWalletClient(walletCli),
 		WithPostage(postage),
 		WithCInsDescription(cInsDescription),
 		WithWalletPass(walletPass),
+		WithDestination(destination),
+		WithFeeEstimator(newFeeEstimator(walletCli)),
+		WithUtxo(utxo),
+		WithMinConf(minConf),
+	}
+	if batchFilePath != "" {
+		return inscribeBatch(walletCli, opts)
+	}
+	opts = append(opts,
 		WithCborMetadata(cborMetadata),
 		WithJsonMetadata(jsonMetadata),
+		WithDelegate(tables.StringToInscriptionId(delegate)),
 	)
+	var inscription *Inscription
+	if inscriptionsFilePath != "" {
+		inscription, err = NewFromPath(inscriptionsFilePath, opts...)
+	} else {
+		inscription, err = NewFromData(nil, nil, opts...)
+	}
 	if err != nil {
 		return err
 	}
 
-	if err := inscription.Wallet().WalletPassphrase(walletPass, 60); err != nil {
-		return err
+	// The external signer of a PSBT signs the commit transaction, the wallet stays locked
+	if !psbtMode {
+		if err := inscription.Wallet().WalletPassphrase(walletPass, 60); err != nil {
+			return err
+		}
+		defer inscription.Wallet().WalletLock()
 	}
-	defer inscription.Wallet().WalletLock()
 
 	// Get all UTXO for all unspent addresses and exclude the UTXO where the inscription
 	if err := inscription.getUtxo(); err != nil {
@@ -186,6 +252,12 @@ func inscribe() error {
 		out := Output{
 			Commit:    inscription.CommitTxId(),
 			Reveal:    inscription.RevealTxId(),
+			FeeRate:   float64(inscription.feeRate) / 1000,
+			CommitFee: inscription.commitFee,
+			RevealFee: TxFee{
+				VSize: inscription.revealVSize,
+				Fee:   inscription.revealFee,
+			},
 			TotalFees: inscription.totalFee,
 		}
 		outData, _ := json.MarshalIndent(out, "", "\t")
@@ -193,6 +265,14 @@ func inscribe() error {
 		return nil
 	}
 
+	if psbtMode {
+		session, err := inscription.session()
+		if err != nil {
+			return err
+		}
+		return journalPsbt(walletCli, session, inscription.commitTx, inscription.utxo)
+	}
+
 	// Sign the commit transaction
 	if err := inscription.SignCommitTx(); err != nil {
 		return err
@@ -205,19 +285,151 @@ func inscribe() error {
 		return err
 	}
 
-	// Send the commit transaction
-	commitTxHash, err := walletCli.SendRawTransaction(inscription.commitTx, false)
+	// Journal the session before sending, so that it can be resumed or recovered after a crash
+	session, err := inscription.session()
+	if err != nil {
+		return err
+	}
+	journal, err := NewSessionJournal(defaultSessionDir())
+	if err != nil {
+		return err
+	}
+	if err := journal.Save(session); err != nil {
+		return err
+	}
+
+	// Send the commit transaction and the reveal transaction
+	return sendSession(walletCli, journal, session)
+}
+
+// newWalletClient is a function that creates a client of the wallet RPC server,
+// which is shut down on interrupt.
+func newWalletClient() (*rpcclient.Client, error) {
+	walletCli, err := rpcclient.NewClient(
+		rpcclient.WithClientHost(walletUrl),
+		rpcclient.WithClientUser(walletRpcUser),
+		rpcclient.WithClientPassword(walletRpcPass),
+	)
+	if err != nil {
+		return nil, err
+	}
+	signal.AddInterruptHandler(func() {
+		walletCli.Shutdown()
+	})
+	return walletCli, nil
+}
+
+// newFeeEstimator is a function that returns a static fee estimator if the fee rate
+// is set, the wallet node fee estimator for the confirmation target otherwise.
+func newFeeEstimator(walletCli *rpcclient.Client) FeeEstimator {
+	if feeRate > 0 {
+		return NewStaticFeeEstimator(feeRate)
+	}
+	return NewNodeFeeEstimator(walletCli, targetBlocks)
+}
+
+// inscribeBatch is a function that inscribes the inscriptions of the batch manifest
+// from one commit transaction. It prints the transaction ids and the inscription ids.
+func inscribeBatch(walletCli *rpcclient.Client, opts []Option) error {
+	manifest, err := ReadBatchManifest(batchFilePath)
+	if err != nil {
+		return err
+	}
+	batch, err := NewBatch(manifest, opts...)
+	if err != nil {
+		return err
+	}
+
+	if !psbtMode {
+		if err := walletCli.WalletPassphrase(walletPass, 60); err != nil {
+			return err
+		}
+		defer walletCli.WalletLock()
+	}
+
+	// Get all UTXO for all unspent addresses and exclude the UTXO where the inscription
+	if err := batch.getUtxo(); err != nil {
+		return err
+	}
+
+	// Create the commit transaction and the reveal transactions
+	if err := batch.CreateInscriptionTx(); err != nil {
+		if errors.Is(err, InsufficientBalanceError) {
+			log.Log.Warn("no enough balance")
+			return nil
+		}
+		return err
+	}
+
+	if dryRun {
+		log.Log.Info("dry run success")
+		outData, _ := json.MarshalIndent(batch.Output(), "", "\t")
+		fmt.Println(string(outData))
+		return nil
+	}
+
+	if psbtMode {
+		session, err := batch.session()
+		if err != nil {
+			return err
+		}
+		return journalPsbt(walletCli, session, batch.commitTx, batch.utxo)
+	}
+
+	// Sign the commit transaction before the reveal transactions, which spend its outputs
+	if err := batch.SignCommitTx(); err != nil {
+		return err
+	}
+	if err := batch.SignRevealTx(); err != nil {
+		return err
+	}
+	if err := batch.backupPrivKey(); err != nil {
+		return err
+	}
+
+	// Journal the session before sending, so that it can be resumed or recovered after a crash
+	session, err := batch.session()
 	if err != nil {
 		return err
 	}
-	log.Log.Info("commitTxSendSuccess", commitTxHash)
+	journal, err := NewSessionJournal(defaultSessionDir())
+	if err != nil {
+		return err
+	}
+	if err := journal.Save(session); err != nil {
+		return err
+	}
+	if err := sendSession(walletCli, journal, session); err != nil {
+		return err
+	}
+
+	outData, _ := json.MarshalIndent(batch.Output(), "", "\t")
+	fmt.Println(string(outData))
+	return nil
+}
 
-	// Send the reveal transaction
-	revealTxHash, err := walletCli.SendRawTransaction(inscription.revealTx, false)
+// journalPsbt is a function that journals the session of an unsigned commit transaction
+// with its PSBT, and prints the session id and the PSBT for the external signer.
+func journalPsbt(walletCli *rpcclient.Client, session *Session, commitTx *wire.MsgTx, utxo []btcjson.ListUnspentResult) error {
+	packet, err := newCommitPsbt(walletCli, commitTx, utxo)
+	if err != nil {
+		return err
+	}
+	if err := psbtSession(session, packet); err != nil {
+		return err
+	}
+	journal, err := NewSessionJournal(defaultSessionDir())
 	if err != nil {
 		return err
 	}
-	log.Log.Info("revealTxSendSuccess", revealTxHash)
+	if err := journal.Save(session); err != nil {
+		return err
+	}
 
+	outData, _ := json.MarshalIndent(PsbtOutput{
+		Session: session.Id,
+		Psbt:    session.Psbt,
+	}, "", "\t")
+	fmt.Println(string(outData))
 	return nil
 }
@@ -99,7 +99,7 @@ func TestMockInscriptions(t *testing.T) {
 				MediaType:       constants.ContentTypeJson.MediaType().String(),
 				ContentSize:     uint32(len(bodyBs)),
 				CInsDescription: tables.CInsDescription{
-					Type:     constants.UnlockConditionTypeBlockchain,
+					Type:     constants.CInsDescriptionTypeBlockchain,
 					Chain:    "309",
 					Contract: "ckt1qqexmutxu0c2jq9q4msy8cc6fh4q7q02xvr7dc347zw3ks3qka0m6qggqupnqt6y5nu39j0704jvw770esjfdzulzsyqwqes9az2f7gje8l86ex8008ucfyk3w03gk2pfrr",
 				},
@@ -109,7 +109,7 @@ func TestMockInscriptions(t *testing.T) {
 				return err
 			}
 
-			if err := tx.SaveProtocol(&tables.Protocol{
+			if err := tx.SaveProtocol(uint32(i), &tables.Protocol{
 				InscriptionId: insId,
 				Index:         0,
 				SequenceNum:   -i,
@@ -19,10 +19,8 @@ import (
 	"github.com/btcsuite/btcwallet/wallet/txauthor"
 	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/go-playground/validator/v10"
-	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/inscription-c/cins/btcd/rpcclient"
 	"github.com/inscription-c/cins/constants"
-	"github.com/inscription-c/cins/inscription/index"
 	"github.com/inscription-c/cins/inscription/index/tables"
 	"github.com/inscription-c/cins/inscription/log"
 	"github.com/inscription-c/cins/pkg/indexer"
@@ -67,11 +65,23 @@ type Inscription struct {
 	// revealFee is the fee for the reveal transaction.
 	revealFee int64
 
+	// revealVSize is the virtual size of the reveal transaction.
+	revealVSize int64
+
+	// commitFee is the fee and the virtual size of the commit transaction.
+	commitFee TxFee
+
 	totalFee int64
 
-	// utxo is the unspent transaction outputs for the wallet.
+	// utxo is the unspent transaction outputs spent by the commit transaction.
 	utxo []btcjson.ListUnspentResult
 
+	// pinnedUtxo is the unspent transaction outputs the commit transaction always spends.
+	pinnedUtxo []btcjson.ListUnspentResult
+
+	// candidateUtxo is the unspent transaction outputs the coin selection selects from.
+	candidateUtxo []btcjson.ListUnspentResult
+
 	// commitTx is the commit transaction of the inscription.
 	commitTx, revealTx *wire.MsgTx
 
@@ -107,6 +117,9 @@ type Header struct {
 	// ContentEncoding is the encoding of the content of the inscription.
 	ContentEncoding string `json:"content_encoding"`
 
+	// Delegate is the inscription whose content is served in place of an empty body.
+	Delegate *tables.InscriptionId `json:"delegate"`
+
 	// Pointer is the pointer to the content of the inscription.
 	Pointer string `json:"pointer"`
 
@@ -138,6 +151,21 @@ type options struct {
 
 	// indexer is the indexer for the inscription.
 	indexer indexer.IndexerInterface
+
+	// delegate is the delegate inscription id for the inscription.
+	delegate *tables.InscriptionId
+
+	// destination is the address the inscription is sent to.
+	destination string
+
+	// feeEstimator is the fee rate estimator for the transactions.
+	feeEstimator FeeEstimator
+
+	// utxo is the outpoints of the UTXOs the commit transaction must spend.
+	utxo []string
+
+	// minConf is the minimum number of confirmations of the UTXOs spent by the commit transaction.
+	minConf int
 }
 
 // Option is a function type that takes a pointer to an options' struct.
@@ -204,6 +232,51 @@ func WithIndexer(indexer indexer.IndexerInterface) func(*options) {
 	}
 }
 
+// WithDelegate is a function that sets the delegate option for an Inscription.
+// It takes a pointer to the delegate inscription id and returns a function that
+// sets the delegate in the options of an Inscription.
+func WithDelegate(delegate *tables.InscriptionId) func(*options) {
+	return func(options *options) {
+		options.delegate = delegate
+	}
+}
+
+// WithDestination is a function that sets the destination option for an Inscription.
+// It takes a string representing the destination address and returns a function that
+// sets the destination in the options of an Inscription.
+func WithDestination(destination string) func(*options) {
+	return func(options *options) {
+		options.destination = destination
+	}
+}
+
+// WithFeeEstimator is a function that sets the fee estimator option for an Inscription.
+// It takes a FeeEstimator and returns a function that sets the fee estimator in the
+// options of an Inscription.
+func WithFeeEstimator(feeEstimator FeeEstimator) func(*options) {
+	return func(options *options) {
+		options.feeEstimator = feeEstimator
+	}
+}
+
+// WithUtxo is a function that sets the pinned UTXOs option for an Inscription.
+// It takes the outpoints of the UTXOs the commit transaction must spend and returns
+// a function that sets them in the options of an Inscription.
+func WithUtxo(outpoints []string) func(*options) {
+	return func(options *options) {
+		options.utxo = outpoints
+	}
+}
+
+// WithMinConf is a function that sets the minimum confirmations option for an Inscription.
+// It takes the minimum number of confirmations of the UTXOs spent by the commit transaction
+// and returns a function that sets it in the options of an Inscription.
+func WithMinConf(minConf int) func(*options) {
+	return func(options *options) {
+		options.minConf = minConf
+	}
+}
+
 // NewFromPath is a function that creates a new Inscription from a given path.
 // It takes a string representing the path and a variadic number of Option functions
 // to set the options for the Inscription. It validates the options, sets the options
@@ -262,6 +335,7 @@ func NewFromData(body []byte, media *constants.Media, inputOpts ...Option) (*Ins
 	inscription := &Inscription{
 		Header: Header{
 			CInsDescription: opts.cInsDescription,
+			Delegate:        opts.delegate,
 			Metadata:        &util.Reader{},
 		},
 		options: opts,
@@ -276,8 +350,10 @@ func NewFromData(body []byte, media *constants.Media, inputOpts ...Option) (*Ins
 		return nil, err
 	}
 
-	// Set the content type of the Inscription
-	inscription.Header.ContentType = media.ContentType
+	// Set the content type of the Inscription, an inscription without body has no media
+	if media != nil {
+		inscription.Header.ContentType = media.ContentType
+	}
 
 	// Initialize the content encoding as an empty string
 	contentEncoding := ""
@@ -324,6 +400,15 @@ func NewFromData(body []byte, media *constants.Media, inputOpts ...Option) (*Ins
 	return inscription, nil
 }
 
+// feeRateEstimator is a method that returns the fee estimator of the options,
+// the wallet backend node estimator if no fee estimator is set.
+func (o *options) feeRateEstimator() FeeEstimator {
+	if o.feeEstimator != nil {
+		return o.feeEstimator
+	}
+	return NewNodeFeeEstimator(o.walletClient, DefaultTargetBlocks)
+}
+
 // Wallet is a method of the Inscription struct. It returns the wallet client of the Inscription.
 func (i *Inscription) Wallet() *rpcclient.Client {
 	return i.options.walletClient
@@ -405,29 +490,11 @@ func (i *Inscription) rawTx(tx *wire.MsgTx, noWitness ...bool) string {
 // generates a temporary private key, builds the reveal transaction, and builds the
 // commit transaction. It returns an error if there is an error in any of the steps.
 func (i *Inscription) CreateInscriptionTx() error {
-	backendVersion, err := i.Wallet().BackendVersion()
+	feeRate, err := i.options.feeRateEstimator().EstimateFeeRate()
 	if err != nil {
 		return err
 	}
-
-	var feeRate float64
-	if backendVersion == rpcclient.Btcd {
-		feeRate, err = i.Wallet().EstimateFee(10)
-		if err != nil {
-			return err
-		}
-	} else {
-		var resp *btcjson.EstimateSmartFeeResult
-		resp, err = i.Wallet().EstimateSmartFee(10, &btcjson.EstimateModeConservative)
-		if err != nil {
-			return err
-		}
-		if len(resp.Errors) > 0 {
-			return errors.New(gconv.String(resp.Errors))
-		}
-		feeRate = *resp.FeeRate
-	}
-	i.feeRate = int64(index.AmountToSat(feeRate))
+	i.feeRate = feeRate
 
 	// gen temporary priKey
 	priKey, err := btcec.NewPrivateKey()
@@ -445,6 +512,7 @@ func (i *Inscription) CreateInscriptionTx() error {
 	if err := i.BuildCommitTx(); err != nil {
 		return err
 	}
+	i.linkRevealTx()
 	return nil
 }
 
@@ -455,65 +523,93 @@ func (i *Inscription) CreateInscriptionTx() error {
 // the input scripts for the transaction. It returns an error if there is
 // an error in any of the steps.
 func (i *Inscription) BuildCommitTx() error {
+	recipientScript, err := util.AddressScript(i.revealTxAddress.String(), util.ActiveNet.Params)
+	if err != nil {
+		return err
+	}
+	commitTx, utxo, fee, err := buildCommitTx(i.Wallet(), i.pinnedUtxo, i.candidateUtxo, []*wire.TxOut{
+		wire.NewTxOut(int64(i.options.postage)+i.revealFee, recipientScript),
+	}, i.feeRate)
+	if err != nil {
+		return err
+	}
+	i.commitTx = commitTx
+	i.utxo = utxo
+	i.commitFee = fee
+	i.totalFee += fee.Fee
+	return nil
+}
+
+// buildCommitTx is a function that builds a commit transaction paying the given outputs,
+// spending the pinned UTXOs and the candidate UTXOs picked by the coin selection, with the
+// change sent to a new change address of the wallet. The fee is calculated on the estimated
+// size of the signed transaction. It returns the commit transaction, the spent UTXOs, its
+// size and fee, and InsufficientBalanceError if the UTXOs cannot pay the outputs and the fee.
+func buildCommitTx(walletCli *rpcclient.Client, pinned, candidates []btcjson.ListUnspentResult, outputs []*wire.TxOut, feeRate int64) (
+	*wire.MsgTx, []btcjson.ListUnspentResult, TxFee, error) {
 	var inTotal, outTotal int64
 	commitTx := wire.NewMsgTx(2)
-	i.commitTx = commitTx
+
+	// output begin
+	for _, out := range outputs {
+		commitTx.AddTxOut(out)
+		outTotal += out.Value
+	}
+	// output end
+
+	utxo, err := selectCoins(commitTx, pinned, candidates, feeRate)
+	if err != nil {
+		return nil, nil, TxFee{}, err
+	}
 
 	// input begin
-	for _, v := range i.utxo {
+	for _, v := range utxo {
 		hash, err := chainhash.NewHashFromStr(v.TxID)
 		if err != nil {
-			return err
+			return nil, nil, TxFee{}, err
 		}
 		txIn := wire.NewTxIn(&wire.OutPoint{
 			Hash:  *hash,
 			Index: v.Vout,
 		}, nil, nil)
 		commitTx.AddTxIn(txIn)
-		inTotal += decimal.NewFromFloat(v.Amount).Mul(decimal.NewFromInt(int64(constants.OneBtc))).IntPart()
+		inTotal += utxoValue(v)
 	}
 	// input end
 
-	// output begin
-	recipientScript, err := util.AddressScript(i.revealTxAddress.String(), util.ActiveNet.Params)
-	if err != nil {
-		return err
-	}
-	commitTx.AddTxOut(wire.NewTxOut(int64(postage)+i.revealFee, recipientScript))
-	outTotal += int64(postage) + i.revealFee
-	// output end
-
 	// change calculate
-	change := inTotal - outTotal - CalculateTxFee(commitTx, i.feeRate)
+	change := inTotal - outTotal - calculateWeightFee(signedTxWeight(commitTx, utxo), feeRate)
 	if change < 0 {
-		return InsufficientBalanceError
+		return nil, nil, TxFee{}, InsufficientBalanceError
 	}
 
 	// change output
-	commitTxChangeAddr, err := i.Wallet().GetRawChangeAddressType(constants.DefaultWalletName, constants.AddressTypeP2shSegWit)
+	commitTxChangeAddr, err := walletCli.GetRawChangeAddressType(constants.DefaultWalletName, constants.AddressTypeP2shSegWit)
 	if err != nil {
-		return err
+		return nil, nil, TxFee{}, err
 	}
 	changeScript, err := util.AddressScript(commitTxChangeAddr.String(), util.ActiveNet.Params)
 	if err != nil {
-		return err
+		return nil, nil, TxFee{}, err
 	}
 	commitTx.AddTxOut(wire.NewTxOut(change, changeScript))
-	fee := CalculateTxFee(commitTx, i.feeRate)
-	i.totalFee += fee
+	weight := signedTxWeight(commitTx, utxo)
+	fee := calculateWeightFee(weight, feeRate)
 	change = inTotal - outTotal - fee
 	commitTx.TxOut[len(commitTx.TxOut)-1].Value = change
 	if change < constants.DustLimit {
 		commitTx.TxOut = commitTx.TxOut[:len(commitTx.TxOut)-1]
+		weight = signedTxWeight(commitTx, utxo)
+		fee = inTotal - outTotal
 	}
-	return nil
+	return commitTx, utxo, TxFee{VSize: weightToVSize(weight), Fee: fee}, nil
 }
 
 // BuildRevealTx is a method of the Inscription struct. It is responsible
 // for building the reveal transaction of the Inscription. It generates a
 // temporary key, builds the reveal script, creates the reveal transaction,
-// and clears the input scripts for the transaction. It returns an error if
-// there is an error in any of the steps.
+// and calculates its size and fee. It returns an error if there is an error
+// in any of the steps.
 func (i *Inscription) BuildRevealTx() error {
 	// Generate a temporary key
 	i.internalKey = i.priKey.PubKey()
@@ -548,35 +644,28 @@ func (i *Inscription) BuildRevealTx() error {
 		return err
 	}
 	revealTxWitness = append(revealTxWitness, controlBlockBytes)
-	taprootScript, err := txscript.PayToAddrScript(i.revealTxAddress)
-	if err != nil {
-		return err
-	}
 
 	// Create the transaction input
 	revealTxIn := &wire.TxIn{
-		SignatureScript: taprootScript,
-		Witness:         revealTxWitness,
-		Sequence:        0xFFFFFFFD,
+		Witness:  revealTxWitness,
+		Sequence: 0xFFFFFFFD,
 	}
 
 	// Create the transaction output
-	destAddrScript, err := util.AddressScript(strings.TrimSpace(destination), util.ActiveNet.Params)
+	destAddrScript, err := util.AddressScript(strings.TrimSpace(i.options.destination), util.ActiveNet.Params)
 	if err != nil {
 		return err
 	}
-	revealTxOutput := wire.NewTxOut(int64(postage), destAddrScript)
+	revealTxOutput := wire.NewTxOut(int64(i.options.postage), destAddrScript)
 
 	// Create the reveal transaction
 	revealTx := wire.NewMsgTx(2)
 	i.revealTx = revealTx
 	revealTx.AddTxIn(revealTxIn)
 	revealTx.AddTxOut(revealTxOutput)
+	i.revealVSize = weightToVSize(txWeight(revealTx))
 	i.revealFee = CalculateTxFee(revealTx, i.feeRate)
 	i.totalFee += i.revealFee
-
-	// Clear the input scripts for the transaction
-	revealTxIn.SignatureScript = nil
 	return nil
 }
 
@@ -586,37 +675,42 @@ func (i *Inscription) BuildRevealTx() error {
 // hashes, and signs the transaction inputs. It returns an error if there is an
 // error in any of the steps.
 func (i *Inscription) SignCommitTx() error {
+	return signCommitTx(i.Wallet(), i.commitTx, i.utxo)
+}
+
+// signCommitTx is a function that signs every input of the commit transaction
+// with the private key of the spent UTXO dumped from the wallet.
+func signCommitTx(walletCli *rpcclient.Client, commitTx *wire.MsgTx, utxo []btcjson.ListUnspentResult) error {
 	priKeyMap := make(map[string]*btcutil.WIF)
 	prevPkScripts := make([][]byte, 0)
 	prevPkScriptsMap := make(map[string][]byte)
 	inputValues := make([]btcutil.Amount, 0)
 
-	for j := 0; j < len(i.utxo); j++ {
-		utxo := i.utxo[j]
-		address, err := btcutil.DecodeAddress(utxo.Address, util.ActiveNet.Params)
+	for j := 0; j < len(utxo); j++ {
+		address, err := btcutil.DecodeAddress(utxo[j].Address, util.ActiveNet.Params)
 		if err != nil {
 			return err
 		}
-		wif, err := i.Wallet().DumpPrivKey(address)
+		wif, err := walletCli.DumpPrivKey(address)
 		if err != nil {
 			return err
 		}
 		priKeyMap[address.String()] = wif
-		pkScript, err := hex.DecodeString(utxo.ScriptPubKey)
+		pkScript, err := hex.DecodeString(utxo[j].ScriptPubKey)
 		if err != nil {
 			return err
 		}
 		prevPkScriptsMap[address.String()] = pkScript
 		prevPkScripts = append(prevPkScripts, pkScript)
 
-		amount, err := btcutil.NewAmount(utxo.Amount)
+		amount, err := btcutil.NewAmount(utxo[j].Amount)
 		if err != nil {
 			return err
 		}
 		inputValues = append(inputValues, amount)
 	}
 
-	if err := txauthor.AddAllInputScripts(i.commitTx, prevPkScripts, inputValues, secretSource{
+	if err := txauthor.AddAllInputScripts(commitTx, prevPkScripts, inputValues, secretSource{
 		priKeys: priKeyMap,
 		scripts: prevPkScriptsMap,
 	}); err != nil {
@@ -633,13 +727,12 @@ func (i *Inscription) SignRevealTx() error {
 	// This block of code is part of the signRevealTx method of the Inscription struct.
 	// It is responsible for signing the reveal transaction of the Inscription.
 
-	// First, it gets the hash of the commit transaction and sets it as the previous outpoint of the reveal transaction input.
-	commitHash := i.commitTx.TxHash()
-	i.revealTx.TxIn[i.vout].PreviousOutPoint = *wire.NewOutPoint(&commitHash, uint32(i.vout))
+	// First, it sets the commit transaction output as the previous outpoint of the reveal transaction input.
+	i.linkRevealTx()
 
 	// It creates a new MultiPrevOutFetcher to fetch previous outputs.
 	prevFetcher := txscript.NewMultiPrevOutFetcher(map[wire.OutPoint]*wire.TxOut{
-		i.revealTx.TxIn[i.vout].PreviousOutPoint: {
+		i.revealTx.TxIn[0].PreviousOutPoint: {
 			Value:    i.commitTx.TxOut[i.vout].Value,
 			PkScript: i.commitTx.TxOut[i.vout].PkScript,
 		},
@@ -662,13 +755,20 @@ func (i *Inscription) SignRevealTx() error {
 
 	// It serializes the signature and sets it as the witness of the reveal transaction input.
 	sig := signature.Serialize()
-	i.revealTx.TxIn[i.vout].Witness[0] = sig
+	i.revealTx.TxIn[0].Witness[0] = sig
 	return nil
 }
 
+// linkRevealTx is a method of the Inscription struct. It points the input of the
+// reveal transaction to the output of the commit transaction.
+func (i *Inscription) linkRevealTx() {
+	commitHash := i.commitTx.TxHash()
+	i.revealTx.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(&commitHash, uint32(i.vout))
+}
+
 // InscriptionToScript is a method of the Inscription struct. It is
 // responsible for appending the reveal script to the script builder. It adds the
-// protocol ID, content type, metadata, content encoding, and body to the script builder.
+// protocol ID, content type, metadata, content encoding, delegate, and body to the script builder.
 // It returns an error if there is an error in any of the steps.
 func InscriptionToScript(
 	internalKey *btcec.PublicKey,
@@ -689,9 +789,13 @@ func InscriptionToScript(
 		AddOp(txscript.OP_IF).
 		AddData([]byte(constants.ProtocolId)).
 		AddData([]byte(constants.CInsDescription)).
-		AddData(header.CInsDescription.Data()).
-		AddOp(txscript.OP_1).
-		AddData(header.ContentType.Bytes())
+		AddData(header.CInsDescription.Data())
+
+	// If content type exists, add it to the script builder
+	if header.ContentType != "" {
+		scriptBuilder.AddOp(txscript.OP_1)
+		scriptBuilder.AddData(header.ContentType.Bytes())
+	}
 
 	// If metadata exists, add it to the script builder
 	// The metadata is divided into chunks of 520 bytes and each chunk is added to the script builder
@@ -715,6 +819,12 @@ func InscriptionToScript(
 		scriptBuilder.AddData([]byte(header.ContentEncoding))
 	}
 
+	// If delegate exists, add it to the script builder
+	if header.Delegate != nil {
+		scriptBuilder.AddOp(txscript.OP_11)
+		scriptBuilder.AddData(header.Delegate.Bytes())
+	}
+
 	// If body exists, add it to the script builder
 	// The body is divided into chunks of 520 bytes and each chunk is added to the script builder
 	if body.Len() > 0 {
@@ -783,51 +893,58 @@ func RevealScriptAddress(internalKey *btcec.PublicKey, revealScript []byte) (
 
 // getUtxo is a method of the Inscription struct.
 // It is responsible for getting the unspent transaction outputs (UTXOs) for the wallet.
-// It first lists the unspent and locked UTXOs, then filters out the UTXOs that are already used in inscriptions.
+// It lists the pinned UTXOs and the candidate UTXOs of the coin selection, excluding
+// locked UTXOs and the UTXOs holding inscriptions.
 // It returns an error if there is an error in any of the steps.
 func (i *Inscription) getUtxo() error {
-	// List unspent UTXOs
-	unspentUtxo, err := i.Wallet().ListUnspent()
+	pinned, candidates, err := listUtxo(i.Wallet(), i.options.indexer, i.options.minConf, i.options.utxo)
 	if err != nil {
 		return err
 	}
-
-	utxo := make([]btcjson.ListUnspentResult, 0)
-	for _, v := range unspentUtxo {
-		hash, _ := chainhash.NewHashFromStr(v.TxID)
-		outpoint := wire.NewOutPoint(hash, v.Vout)
-		resp, err := i.options.indexer.Outpoint(outpoint.String())
-		if err != nil {
-			return err
-		}
-		if len(resp.Inscriptions) == 0 {
-			utxo = append(utxo, v)
-		}
-	}
-	i.utxo = utxo
+	i.pinnedUtxo = pinned
+	i.candidateUtxo = candidates
 	return nil
 }
 
 // backupPrivKey is a method of the Inscription struct.
 // It is responsible for backing up the private key of the Inscription.
 // If the noBackup flag is set, it returns immediately.
-// Otherwise, it unlocks the wallet, generates a Wallet Import Format (WIF) from the private key,
-// imports the WIF into the wallet, and then locks the wallet.
+// Otherwise, it generates a Wallet Import Format (WIF) from the private key
+// and imports the WIF into the wallet.
 // It returns an error if there is an error in any of the steps.
 func (i *Inscription) backupPrivKey() error {
+	return backupPrivKey(i.Wallet(), i.priKey)
+}
+
+// backupPrivKey is a function that imports the temporary private key into the wallet,
+// unless the noBackup flag is set.
+func backupPrivKey(walletCli *rpcclient.Client, priKey *btcec.PrivateKey) error {
 	if noBackup {
 		return nil
 	}
-	wif, err := btcutil.NewWIF(i.priKey, util.ActiveNet.Params, true)
+	wif, err := btcutil.NewWIF(priKey, util.ActiveNet.Params, true)
 	if err != nil {
 		return err
 	}
-	if err := i.Wallet().ImportPrivKey(wif); err != nil {
+	if err := walletCli.ImportPrivKey(wif); err != nil {
 		return err
 	}
 	return nil
 }
 
+// session is a method of the Inscription struct. It returns the session of the
+// signed commit and reveal transactions of the Inscription.
+func (i *Inscription) session() (*Session, error) {
+	controlBlock, err := i.controlBlock.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	return newSession(i.priKey, i.commitTx, []*wire.MsgTx{i.revealTx},
+		[]*SessionReveal{newSessionReveal(i.vout, i.revealScript, controlBlock)},
+		[]string{tables.NewInscriptionId(i.RevealTxId(), 0).String()},
+	)
+}
+
 // Data is a method of the Inscription struct. It returns the body of the inscription.
 func (i *Inscription) Data() []byte {
 	return i.body
@@ -839,8 +956,13 @@ func (i *Inscription) Data() []byte {
 // If the calculated fee is less than the dust limit, it sets the fee to the dust limit.
 // It returns the calculated fee.
 func CalculateTxFee(tx *wire.MsgTx, feeRate int64) int64 {
-	weight := tx.SerializeSizeStripped()*3 + tx.SerializeSize()
-	fee := decimal.NewFromInt(int64(weight)).
+	return calculateWeightFee(txWeight(tx), feeRate)
+}
+
+// calculateWeightFee is a function that calculates the fee of a transaction
+// weight at a fee rate in satoshis per kilo virtual byte, at least the dust limit.
+func calculateWeightFee(weight int64, feeRate int64) int64 {
+	fee := decimal.NewFromInt(weight).
 		Div(decimal.NewFromInt(4)).
 		Div(decimal.NewFromInt(1000)). //Ceil().
 		Mul(decimal.NewFromInt(feeRate)).IntPart()
@@ -877,7 +999,10 @@ func (s secretSource) ChainParams() *chaincfg.Params {
 }
 
 type Output struct {
-	Commit    string `json:"commit"`
-	Reveal    string `json:"reveal"`
-	TotalFees int64  `json:"total_fees"`
+	Commit    string  `json:"commit"`
+	Reveal    string  `json:"reveal"`
+	FeeRate   float64 `json:"fee_rate"`
+	CommitFee TxFee   `json:"commit_fee"`
+	RevealFee TxFee   `json:"reveal_fee"`
+	TotalFees int64   `json:"total_fees"`
 }
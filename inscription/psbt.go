@@ -0,0 +1,275 @@
+package inscription
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	txscript2 "github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"os"
+	"strings"
+)
+
+// PsbtOutput is the session and the unsigned commit PSBT of an inscribe for an external signer.
+type PsbtOutput struct {
+	Session string `json:"session"`
+	Psbt    string `json:"psbt"`
+}
+
+// newCommitPsbt is a function that creates the PSBT of an unsigned commit transaction
+// spending the given UTXOs, with the previous transactions of the inputs fetched from
+// the wallet. A witness input whose previous transaction is unknown to the wallet only
+// gets its previous output. The inputs are then decorated by the wallet, see decorateCommitPsbt.
+func newCommitPsbt(walletCli *rpcclient.Client, commitTx *wire.MsgTx, utxo []btcjson.ListUnspentResult) (*psbt.Packet, error) {
+	prevTxs := make(map[chainhash.Hash]*wire.MsgTx, len(utxo))
+	for _, v := range utxo {
+		hash, err := chainhash.NewHashFromStr(v.TxID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := prevTxs[*hash]; ok {
+			continue
+		}
+		resp, err := walletCli.GetTransaction(hash)
+		if err != nil {
+			if _, isWitness := utxoRedeemWeight(v); isWitness {
+				continue
+			}
+			return nil, fmt.Errorf("get previous transaction %s: %w", v.TxID, err)
+		}
+		prevTx, err := deserializeTx(resp.Hex)
+		if err != nil {
+			return nil, err
+		}
+		prevTxs[*hash] = prevTx
+	}
+	packet, err := commitPsbt(commitTx, utxo, prevTxs)
+	if err != nil {
+		return nil, err
+	}
+	return decorateCommitPsbt(walletCli, packet, utxo)
+}
+
+// decorateCommitPsbt is a function that adds the BIP32 derivation paths and the redeem
+// scripts of the wallet to the inputs of a commit PSBT, with walletprocesspsbt without
+// signing, so that an external signer holding the keys of the wallet can sign them.
+func decorateCommitPsbt(walletCli *rpcclient.Client, packet *psbt.Packet, utxo []btcjson.ListUnspentResult) (*psbt.Packet, error) {
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := walletCli.WalletProcessPsbt(b64, btcjson.Bool(false), rpcclient.SigHashAll, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decorate psbt: %w", err)
+	}
+	decorated, err := decodePsbt(resp.Psbt)
+	if err != nil {
+		return nil, err
+	}
+	if decorated.UnsignedTx.TxHash() != packet.UnsignedTx.TxHash() {
+		return nil, fmt.Errorf("wallet changed the commit transaction %s of the psbt", packet.UnsignedTx.TxHash())
+	}
+	if err := checkCommitPsbt(decorated, utxo); err != nil {
+		return nil, err
+	}
+	return decorated, nil
+}
+
+// checkCommitPsbt is a function that checks that an external signer can sign the inputs
+// of a commit PSBT: a script hash input needs the redeem script of the nested P2WPKH.
+func checkCommitPsbt(packet *psbt.Packet, utxo []btcjson.ListUnspentResult) error {
+	for idx, v := range utxo {
+		pkScript, err := hex.DecodeString(v.ScriptPubKey)
+		if err != nil {
+			return err
+		}
+		if txscript2.GetScriptClass(pkScript) != txscript2.ScriptHashTy {
+			continue
+		}
+		redeemScript := packet.Inputs[idx].RedeemScript
+		if !txscript2.IsPayToWitnessPubKeyHash(redeemScript) {
+			return fmt.Errorf("no nested P2WPKH redeem script of utxo %s in the wallet", utxoOutpoint(v))
+		}
+	}
+	return nil
+}
+
+// commitPsbt is a function that creates the PSBT of an unsigned commit transaction
+// spending the given UTXOs, in the order of its inputs. Witness inputs get their
+// previous output, and every input gets its previous transaction if it is known.
+func commitPsbt(commitTx *wire.MsgTx, utxo []btcjson.ListUnspentResult, prevTxs map[chainhash.Hash]*wire.MsgTx) (*psbt.Packet, error) {
+	if len(utxo) != len(commitTx.TxIn) {
+		return nil, fmt.Errorf("commit transaction has %d inputs, %d utxo", len(commitTx.TxIn), len(utxo))
+	}
+	packet, err := psbt.NewFromUnsignedTx(commitTx)
+	if err != nil {
+		return nil, err
+	}
+	for idx, v := range utxo {
+		if outpoint := commitTx.TxIn[idx].PreviousOutPoint.String(); outpoint != utxoOutpoint(v) {
+			return nil, fmt.Errorf("commit transaction input %d spends %s, not %s", idx, outpoint, utxoOutpoint(v))
+		}
+		pkScript, err := hex.DecodeString(v.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		if _, isWitness := utxoRedeemWeight(v); isWitness {
+			packet.Inputs[idx].WitnessUtxo = wire.NewTxOut(utxoValue(v), pkScript)
+		}
+		if prevTx, ok := prevTxs[commitTx.TxIn[idx].PreviousOutPoint.Hash]; ok {
+			packet.Inputs[idx].NonWitnessUtxo = prevTx
+		}
+		if packet.Inputs[idx].WitnessUtxo == nil && packet.Inputs[idx].NonWitnessUtxo == nil {
+			return nil, fmt.Errorf("no previous transaction of utxo %s", utxoOutpoint(v))
+		}
+		packet.Inputs[idx].SighashType = txscript2.SigHashAll
+	}
+	return packet, nil
+}
+
+// psbtSession is a function that turns the session of an unsigned commit transaction
+// into a session waiting for the external signer of the commit PSBT.
+func psbtSession(session *Session, packet *psbt.Packet) error {
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return err
+	}
+	session.Status = SessionStatusUnsigned
+	session.Psbt = b64
+	return nil
+}
+
+// decodePsbt is a function that decodes a PSBT from base64 or hex, or from a file
+// holding the PSBT in base64, hex or binary.
+func decodePsbt(data string) (*psbt.Packet, error) {
+	raw := []byte(strings.TrimSpace(data))
+	if fileData, err := os.ReadFile(data); err == nil {
+		raw = bytes.TrimSpace(fileData)
+	}
+	if decoded, err := hex.DecodeString(string(raw)); err == nil {
+		raw = decoded
+	}
+	b64 := !bytes.HasPrefix(raw, []byte("psbt\xff"))
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(raw), b64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid psbt: %w", err)
+	}
+	return packet, nil
+}
+
+// finalizeSession is a function that finalizes the signed commit PSBT of a session
+// waiting for its signer, then links the reveal transactions to the final commit
+// transaction and signs them with the temporary key. The session is saved under the
+// id of the final commit transaction, which differs from the id of the unsigned one
+// when the signer adds signature scripts.
+func finalizeSession(journal *SessionJournal, session *Session, packet *psbt.Packet) error {
+	if session.Status != SessionStatusUnsigned {
+		return fmt.Errorf("session %s is not waiting for a signed psbt, status %s", session.Id, session.Status)
+	}
+	commitTx, err := session.Commit()
+	if err != nil {
+		return err
+	}
+	if packet.UnsignedTx.TxHash() != commitTx.TxHash() {
+		return fmt.Errorf("psbt transaction %s is not the commit transaction of session %s",
+			packet.UnsignedTx.TxHash(), session.Id)
+	}
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		return fmt.Errorf("finalize psbt: %w", err)
+	}
+	signedTx, err := psbt.Extract(packet)
+	if err != nil {
+		return err
+	}
+	if err := signSessionReveals(session, signedTx); err != nil {
+		return err
+	}
+
+	unsignedId := session.Id
+	signedTxHex, err := serializeTx(signedTx)
+	if err != nil {
+		return err
+	}
+	session.Id = signedTx.TxHash().String()
+	session.Status = SessionStatusSigned
+	session.CommitTx = signedTxHex
+	session.Psbt = ""
+	if err := journal.Save(session); err != nil {
+		return err
+	}
+	if unsignedId != session.Id {
+		return journal.Delete(unsignedId)
+	}
+	return nil
+}
+
+// signSessionReveals is a function that points the inputs of the reveal transactions
+// of a session to the outputs of the commit transaction and signs them with the
+// temporary key. It updates the reveal transactions and the inscription ids of the session.
+func signSessionReveals(session *Session, commitTx *wire.MsgTx) error {
+	priKey, err := session.PriKey()
+	if err != nil {
+		return err
+	}
+	revealScripts := make(map[uint32][]byte, len(session.Reveals))
+	for _, reveal := range session.Reveals {
+		revealScript, err := hex.DecodeString(reveal.Script)
+		if err != nil {
+			return err
+		}
+		revealScripts[reveal.Vout] = revealScript
+	}
+	revealTxs, err := session.Reveal()
+	if err != nil {
+		return err
+	}
+
+	commitHash := commitTx.TxHash()
+	signedTxs := make([]string, 0, len(revealTxs))
+	inscriptions := make([]string, 0, len(session.Reveals))
+	for _, revealTx := range revealTxs {
+		prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(revealTx.TxIn))
+		for _, txIn := range revealTx.TxIn {
+			vout := txIn.PreviousOutPoint.Index
+			if _, ok := revealScripts[vout]; !ok || int(vout) >= len(commitTx.TxOut) {
+				return fmt.Errorf("invalid session, reveal input spends unknown commit output %d", vout)
+			}
+			txIn.PreviousOutPoint.Hash = commitHash
+			prevOuts[txIn.PreviousOutPoint] = commitTx.TxOut[vout]
+		}
+
+		prevFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+		sigHashes := txscript.NewTxSigHashes(revealTx, prevFetcher)
+		for idx, txIn := range revealTx.TxIn {
+			signHash, err := txscript.CalcTapScriptSignatureHash(sigHashes, txscript.SigHashDefault, revealTx, idx,
+				prevFetcher, txscript.NewBaseTapLeaf(revealScripts[txIn.PreviousOutPoint.Index]))
+			if err != nil {
+				return err
+			}
+			signature, err := schnorr.Sign(priKey, signHash)
+			if err != nil {
+				return err
+			}
+			txIn.Witness[0] = signature.Serialize()
+		}
+
+		revealTxHex, err := serializeTx(revealTx)
+		if err != nil {
+			return err
+		}
+		signedTxs = append(signedTxs, revealTxHex)
+		for idx := range revealTx.TxIn {
+			inscriptions = append(inscriptions, tables.NewInscriptionId(revealTx.TxHash().String(), uint32(idx)).String())
+		}
+	}
+	session.RevealTxs = signedTxs
+	session.Inscriptions = inscriptions
+	return nil
+}
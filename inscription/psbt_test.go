@@ -0,0 +1,105 @@
+package inscription
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestFinalizeSession(t *testing.T) {
+	journal, err := NewSessionJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := newTestSession(t)
+	commitTx, err := session.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a nested P2WPKH input, whose signature script changes the commit txid
+	pkScript, _ := hex.DecodeString("a914" + "00112233445566778899aabbccddeeff00112233" + "87")
+	packet, err := commitPsbt(commitTx, []btcjson.ListUnspentResult{{
+		TxID:         commitTx.TxIn[0].PreviousOutPoint.Hash.String(),
+		Vout:         commitTx.TxIn[0].PreviousOutPoint.Index,
+		ScriptPubKey: hex.EncodeToString(pkScript),
+		Amount:       0.0002,
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, packet.Inputs[0].WitnessUtxo.Value, int64(20000))
+	assert.Equal(t, psbtSession(session, packet), nil)
+	assert.Equal(t, journal.Save(session), nil)
+
+	// the external signer finalizes the input
+	signed, err := decodePsbt(session.Psbt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var witness bytes.Buffer
+	assert.Equal(t, psbt.WriteTxWitness(&witness, wire.TxWitness{make([]byte, 72), make([]byte, 33)}), nil)
+	signed.Inputs[0].FinalScriptSig = append([]byte{txscript.OP_DATA_22, txscript.OP_0, txscript.OP_DATA_20}, make([]byte, 20)...)
+	signed.Inputs[0].FinalScriptWitness = witness.Bytes()
+
+	unsignedId := session.Id
+	assert.Equal(t, finalizeSession(journal, session, signed), nil)
+	assert.Equal(t, session.Status, SessionStatusSigned)
+	assert.Assert(t, session.Id != unsignedId)
+	_, err = journal.Load(unsignedId)
+	assert.Equal(t, err, ErrSessionNotFound)
+	_, err = journal.Load(session.Id)
+	assert.Equal(t, err, nil)
+
+	// the reveal spends the final commit transaction
+	signedTx, err := session.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, signedTx.TxHash().String(), session.Id)
+	revealTxs, err := session.Reveal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	revealTx := revealTxs[0]
+	assert.Equal(t, revealTx.TxIn[0].PreviousOutPoint.Hash.String(), session.Id)
+	assert.DeepEqual(t, session.Inscriptions, []string{tables.NewInscriptionId(revealTx.TxHash().String(), 0).String()})
+
+	prevOut := signedTx.TxOut[0]
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	engine, err := txscript.NewEngine(prevOut.PkScript, revealTx, 0, txscript.StandardVerifyFlags,
+		nil, txscript.NewTxSigHashes(revealTx, prevFetcher), prevOut.Value, prevFetcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, engine.Execute(), nil)
+
+	assert.Assert(t, finalizeSession(journal, session, signed) != nil)
+}
+
+func TestCheckCommitPsbt(t *testing.T) {
+	commitTx := wire.NewMsgTx(2)
+	commitTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	commitTx.AddTxOut(wire.NewTxOut(10000, nil))
+	pkScript, _ := hex.DecodeString("a914" + "00112233445566778899aabbccddeeff00112233" + "87")
+	utxo := []btcjson.ListUnspentResult{{
+		TxID:         commitTx.TxIn[0].PreviousOutPoint.Hash.String(),
+		ScriptPubKey: hex.EncodeToString(pkScript),
+		Amount:       0.0002,
+	}}
+	packet, err := commitPsbt(commitTx, utxo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signer of a nested P2WPKH input needs its redeem script
+	assert.ErrorContains(t, checkCommitPsbt(packet, utxo), "no nested P2WPKH redeem script")
+	packet.Inputs[0].RedeemScript = append([]byte{txscript.OP_0, txscript.OP_DATA_20}, make([]byte, 20)...)
+	assert.Equal(t, checkCommitPsbt(packet, utxo), nil)
+}
@@ -0,0 +1,226 @@
+package inscription
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/log"
+	"github.com/inscription-c/cins/pkg/signal"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// ResumeCmd sends the transactions of the journaled inscribe sessions which were not sent,
+// of one session if its id is given, of all unfinished sessions otherwise.
+var ResumeCmd = &cobra.Command{
+	Use:   "resume [session_id]",
+	Short: "send the unsent commit and reveal transactions of inscribe sessions",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := resume(args); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		signal.SimulateInterrupt()
+		<-signal.InterruptHandlersDone
+	},
+}
+
+// recoverForce recovers a session whose reveal transactions are sent.
+var recoverForce bool
+
+// RecoverCmd sweeps the commit outputs of an inscribe session back to the wallet,
+// when its reveal transaction cannot be sent.
+var RecoverCmd = &cobra.Command{
+	Use:   "recover <session_id>",
+	Short: "sweep the commit outputs of an inscribe session back to the wallet",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := recoverCommit(args[0]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		signal.SimulateInterrupt()
+		<-signal.InterruptHandlersDone
+	},
+}
+
+// FinalizeCmd finalizes the commit PSBT of an inscribe session signed by an external signer,
+// or by the wallet if no PSBT is given, then signs and sends the reveal transactions.
+var FinalizeCmd = &cobra.Command{
+	Use:   "finalize <session_id> [psbt]",
+	Short: "finalize the signed commit psbt of an inscribe session and send its transactions",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := finalize(args); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		signal.SimulateInterrupt()
+		<-signal.InterruptHandlersDone
+	},
+}
+
+func init() {
+	RecoverCmd.Flags().BoolVarP(&recoverForce, "force", "", false, "recover a session whose reveal transactions are sent, double-spending them")
+	Cmd.AddCommand(ResumeCmd, RecoverCmd, FinalizeCmd)
+}
+
+// resume is a function that sends the unsent transactions of the given session,
+// or of all the unfinished sessions, and prints the sessions.
+func resume(args []string) error {
+	if err := initConfig(); err != nil {
+		return err
+	}
+	journal, err := NewSessionJournal(defaultSessionDir())
+	if err != nil {
+		return err
+	}
+
+	var sessions []*Session
+	if len(args) > 0 {
+		session, err := journal.Load(args[0])
+		if err != nil {
+			return err
+		}
+		if session.Done() {
+			return fmt.Errorf("session %s is finished, status %s", session.Id, session.Status)
+		}
+		sessions = append(sessions, session)
+	} else {
+		list, err := journal.List()
+		if err != nil {
+			return err
+		}
+		for _, session := range list {
+			if !session.Done() {
+				sessions = append(sessions, session)
+			}
+		}
+	}
+	if len(sessions) == 0 {
+		log.Log.Info("no unfinished session")
+		return nil
+	}
+
+	walletCli, err := newWalletClient()
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := sendSession(walletCli, journal, session); err != nil {
+			return fmt.Errorf("session %s: %w", session.Id, err)
+		}
+	}
+
+	// the sessions hold the temporary keys, only their state is printed
+	out := make([]SessionOutput, 0, len(sessions))
+	for _, session := range sessions {
+		out = append(out, SessionOutput{
+			Id:           session.Id,
+			Status:       session.Status,
+			Inscriptions: session.Inscriptions,
+		})
+	}
+	outData, _ := json.MarshalIndent(out, "", "\t")
+	fmt.Println(string(outData))
+	return nil
+}
+
+// SessionOutput is the state of a resumed session.
+type SessionOutput struct {
+	Id           string   `json:"id"`
+	Status       string   `json:"status"`
+	Inscriptions []string `json:"inscriptions"`
+}
+
+// recoverCommit is a function that sweeps the commit outputs of a session back to the
+// wallet and prints the id of the sweep transaction.
+func recoverCommit(id string) error {
+	if err := initConfig(); err != nil {
+		return err
+	}
+	journal, err := NewSessionJournal(defaultSessionDir())
+	if err != nil {
+		return err
+	}
+	session, err := journal.Load(id)
+	if err != nil {
+		return err
+	}
+
+	walletCli, err := newWalletClient()
+	if err != nil {
+		return err
+	}
+	sweepTx, err := recoverSession(walletCli, journal, session, newFeeEstimator(walletCli), recoverForce)
+	if err != nil {
+		return err
+	}
+
+	outData, _ := json.MarshalIndent(map[string]string{
+		"session":  session.Id,
+		"recovery": sweepTx.TxHash().String(),
+	}, "", "\t")
+	fmt.Println(string(outData))
+	return nil
+}
+
+// finalize is a function that finalizes the signed commit PSBT of a session, given in
+// base64, in hex or in a file, or signed by the wallet with walletprocesspsbt if it is
+// not given. It signs the reveal transactions, sends the transactions and prints the session.
+func finalize(args []string) error {
+	if err := initConfig(); err != nil {
+		return err
+	}
+	journal, err := NewSessionJournal(defaultSessionDir())
+	if err != nil {
+		return err
+	}
+	session, err := journal.Load(args[0])
+	if err != nil {
+		return err
+	}
+	if session.Status != SessionStatusUnsigned {
+		return fmt.Errorf("session %s is not waiting for a signed psbt, status %s", session.Id, session.Status)
+	}
+
+	walletCli, err := newWalletClient()
+	if err != nil {
+		return err
+	}
+	var signedPsbt string
+	if len(args) > 1 {
+		signedPsbt = args[1]
+	} else {
+		if err := walletCli.WalletPassphrase(walletPass, 60); err != nil {
+			return err
+		}
+		resp, err := walletCli.WalletProcessPsbt(session.Psbt, btcjson.Bool(true), rpcclient.SigHashAll, nil)
+		walletCli.WalletLock()
+		if err != nil {
+			return err
+		}
+		signedPsbt = resp.Psbt
+	}
+	packet, err := decodePsbt(signedPsbt)
+	if err != nil {
+		return err
+	}
+
+	if err := finalizeSession(journal, session, packet); err != nil {
+		return err
+	}
+	if err := sendSession(walletCli, journal, session); err != nil {
+		return fmt.Errorf("session %s: %w", session.Id, err)
+	}
+
+	outData, _ := json.MarshalIndent(SessionOutput{
+		Id:           session.Id,
+		Status:       session.Status,
+		Inscriptions: session.Inscriptions,
+	}, "", "\t")
+	fmt.Println(string(outData))
+	return nil
+}
@@ -12,13 +12,27 @@ type SrvConfigs struct {
 		IndexSpendSats string `yaml:"index_spend_sats"`
 		EnablePProf    bool   `yaml:"pprof"`
 		Prometheus     bool   `yaml:"prometheus"`
+		Mempool        bool   `yaml:"mempool"`
+		AdminToken     string `yaml:"admin_token"`
 	} `yaml:"server"`
 	Chain struct {
-		Url      string `yaml:"url"`
-		Username string `yaml:"username"`
-		Password string `yaml:"password"`
+		Url         string `yaml:"url"`
+		Username    string `yaml:"username"`
+		Password    string `yaml:"password"`
+		BlockNotify string `yaml:"block_notify"`
+		ZmqBlock    string `yaml:"zmq_block"`
 	} `yaml:"chain"`
+	Reorg struct {
+		MaxSavepoint      uint32 `yaml:"max_savepoint"`
+		SavepointInterval uint32 `yaml:"savepoint_interval"`
+		ChainTipDistance  uint32 `yaml:"chain_tip_distance"`
+		ReindexHeight     int64  `yaml:"reindex_height"`
+	} `yaml:"reorg"`
 	DB struct {
+		Backend string `yaml:"backend"`
+		Sqlite  struct {
+			Path string `yaml:"path"`
+		} `yaml:"sqlite"`
 		Mysql struct {
 			Addr     string `yaml:"addr"`
 			User     string `yaml:"user"`
@@ -0,0 +1,71 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"net/http"
+	"strings"
+)
+
+// RespAddressInscription is a struct that represents an inscription held by an address.
+type RespAddressInscription struct {
+	InscriptionId  string `json:"inscription_id"`
+	InscriptionNum int64  `json:"inscription_number"`
+	ContentType    string `json:"content_type"`
+	SatPoint       string `json:"satpoint"`
+}
+
+// AddressInscriptions is a handler function for handling address inscriptions requests.
+// It validates the request parameters and calls the doAddressInscriptions function.
+func (h *Handler) AddressInscriptions(ctx *gin.Context) {
+	address := strings.TrimSpace(ctx.Param("address"))
+	if address == "" {
+		ctx.String(http.StatusBadRequest, "missing address")
+		return
+	}
+	page := ctx.Param("page")
+	if page == "" {
+		page = "1"
+	}
+	if gconv.Int(page) < 1 {
+		ctx.String(http.StatusBadRequest, "invalid page")
+		return
+	}
+	if err := h.doAddressInscriptions(ctx, address, gconv.Int(page)); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doAddressInscriptions is a helper function for handling address inscriptions requests.
+// It retrieves a page of the inscriptions currently owned by an address and returns them with their satpoints.
+func (h *Handler) doAddressInscriptions(ctx *gin.Context, address string, page int) error {
+	size := 100
+	list, err := h.DB().FindInscriptionsByCurrentOwner(address, page, size)
+	if err != nil {
+		return err
+	}
+	more := false
+	if len(list) > size {
+		more = true
+		list = list[:size]
+	}
+
+	inscriptions := make([]*RespAddressInscription, 0, len(list))
+	for _, v := range list {
+		inscriptions = append(inscriptions, &RespAddressInscription{
+			InscriptionId:  v.InscriptionId.String(),
+			InscriptionNum: v.InscriptionNum,
+			ContentType:    v.ContentType,
+			SatPoint:       tables.FormatSatPoint(v.Outpoint, v.SatOffset),
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"address":      address,
+		"page_index":   page,
+		"more":         more,
+		"inscriptions": inscriptions,
+	})
+	return nil
+}
@@ -0,0 +1,69 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"net/http"
+	"strings"
+)
+
+// IndexerStatus is a handler function for handling indexer status requests.
+// It returns the indexed block height together with the control state of the indexer.
+func (h *Handler) IndexerStatus(ctx *gin.Context) {
+	if err := h.doIndexerStatus(ctx); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doIndexerStatus is a helper function for handling indexer status requests.
+func (h *Handler) doIndexerStatus(ctx *gin.Context) error {
+	blockCount, err := h.DB().BlockCount()
+	if err != nil {
+		return err
+	}
+	status := h.Indexer().Status()
+	ctx.JSON(http.StatusOK, gin.H{
+		"block_count": blockCount,
+		"paused":      status.Paused,
+		"stopping":    status.Stopping,
+		"stop_height": status.StopHeight,
+	})
+	return nil
+}
+
+// PauseIndexer is a handler function for handling indexer pause requests.
+// The indexer pauses once the block being indexed is committed.
+func (h *Handler) PauseIndexer(ctx *gin.Context) {
+	h.Indexer().Pause()
+	h.IndexerStatus(ctx)
+}
+
+// ResumeIndexer is a handler function for handling indexer resume requests.
+// It also clears the stop height.
+func (h *Handler) ResumeIndexer(ctx *gin.Context) {
+	h.Indexer().Resume()
+	h.IndexerStatus(ctx)
+}
+
+// StopIndexerAt is a handler function for handling indexer stop at height requests.
+// The indexer holds once the block at the height is indexed and committed.
+func (h *Handler) StopIndexerAt(ctx *gin.Context) {
+	heightStr := strings.TrimSpace(ctx.Param("height"))
+	height := gconv.Uint32(heightStr)
+	if heightStr == "" || gconv.String(height) != heightStr {
+		ctx.String(http.StatusBadRequest, "invalid height")
+		return
+	}
+	blockCount, err := h.DB().BlockCount()
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if uint64(height)+1 < uint64(blockCount) {
+		ctx.String(http.StatusBadRequest, "height is already indexed")
+		return
+	}
+	h.Indexer().StopAt(height)
+	h.IndexerStatus(ctx)
+}
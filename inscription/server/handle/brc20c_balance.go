@@ -0,0 +1,45 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// BRC20CBalance is a handler function for handling BRC20C balance requests.
+// It validates the request parameters and calls the doBRC20CBalance function.
+func (h *Handler) BRC20CBalance(ctx *gin.Context) {
+	address := ctx.Param("address")
+	if address == "" {
+		ctx.String(http.StatusBadRequest, "missing address")
+		return
+	}
+	if err := h.doBRC20CBalance(ctx, address); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doBRC20CBalance is a helper function for handling BRC20C balance requests.
+// It retrieves the balances of all BRC20C tokens held by an address and returns them in the response.
+func (h *Handler) doBRC20CBalance(ctx *gin.Context, address string) error {
+	list, err := h.DB().FindBalancesByAddress(address)
+	if err != nil {
+		return err
+	}
+
+	balances := make([]gin.H, 0, len(list))
+	for _, v := range list {
+		balances = append(balances, gin.H{
+			"ticker_id":    v.TkId,
+			"ticker":       v.Ticker,
+			"available":    v.Available,
+			"transferable": v.Transferable,
+			"total":        v.Total,
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"address":  address,
+		"balances": balances,
+	})
+	return nil
+}
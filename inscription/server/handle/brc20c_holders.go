@@ -0,0 +1,62 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/constants"
+	"net/http"
+)
+
+// BRC20CHolders is a handler function for handling BRC20C holders requests.
+// It validates the request parameters and calls the doBRC20CHolders function.
+func (h *Handler) BRC20CHolders(ctx *gin.Context) {
+	tkid := ctx.Param("tkid")
+	page := ctx.Param("page")
+	if page == "" {
+		page = "1"
+	}
+	if !constants.InscriptionIdRegexp.MatchString(tkid) {
+		ctx.String(http.StatusBadRequest, "invalid token id")
+		return
+	}
+	if gconv.Int(page) < 1 {
+		ctx.String(http.StatusBadRequest, "invalid page")
+		return
+	}
+	if err := h.doBRC20CHolders(ctx, tkid, gconv.Int(page)); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doBRC20CHolders is a helper function for handling BRC20C holders requests.
+// It retrieves the holders of a specific BRC20C token ranked by balance and returns them in the response.
+func (h *Handler) doBRC20CHolders(ctx *gin.Context, tkid string, page int) error {
+	pageSize := 100
+	list, err := h.DB().FindHoldersByTkId(tkid, page, pageSize)
+	if err != nil {
+		return err
+	}
+	more := false
+	if len(list) > pageSize {
+		more = true
+		list = list[:pageSize]
+	}
+
+	holders := make([]gin.H, 0, len(list))
+	for _, v := range list {
+		holders = append(holders, gin.H{
+			"address":      v.Address,
+			"available":    v.Available,
+			"transferable": v.Transferable,
+			"total":        v.Total,
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"ticker_id":  tkid,
+		"page_index": page,
+		"more":       more,
+		"holders":    holders,
+	})
+	return nil
+}
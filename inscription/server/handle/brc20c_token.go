@@ -38,17 +38,16 @@ func (h *Handler) doBRC20CToken(ctx *gin.Context, tkid string) error {
 		return nil
 	}
 
-	//if token.Operator == constants.OperationMint {
-	//	//inscriptionId := tables.StringToInscriptionId(token.TkID)
-	//	token, err = h.DB().GetProtocolByInscriptionId(inscriptionId)
-	//	if err != nil {
-	//		return err
-	//	}
-	//	if token.Id == 0 {
-	//		ctx.Status(http.StatusNotFound)
-	//		return nil
-	//	}
-	//}
+	if token.Operator == constants.OperationMint {
+		token, err = h.DB().GetProtocolByInscriptionId(tables.StringToInscriptionId(token.TkId))
+		if err != nil {
+			return err
+		}
+		if token.Id == 0 {
+			ctx.Status(http.StatusNotFound)
+			return nil
+		}
+	}
 
 	resp, err := h.GetBRC20TokenInfo(&token)
 	if err != nil {
@@ -63,10 +62,17 @@ func (h *Handler) doBRC20CToken(ctx *gin.Context, tkid string) error {
 // It returns the token information and any error encountered.
 func (h *Handler) GetBRC20TokenInfo(token *tables.Protocol) (gin.H, error) {
 	//lock := &sync.Mutex{}
+	holders, err := h.DB().CountHoldersByTkId(token.InscriptionId.String())
+	if err != nil {
+		return nil, err
+	}
 	resp := gin.H{
 		"ticker_id":    token.InscriptionId,
 		"ticker":       token.Ticker,
 		"total_supply": token.Max,
+		"limit":        token.Limit,
+		"minted":       token.Minted,
+		"holders":      holders,
 	}
 
 	//errWg := &errgroup.Group{}
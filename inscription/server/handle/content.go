@@ -37,6 +37,21 @@ func (h *Handler) doContent(ctx *gin.Context, inscriptionId string) error {
 		return nil
 	}
 
+	// An inscription without a body of its own serves the content of its delegate
+	if len(inscription.Body) == 0 && inscription.Delegate != "" {
+		delegate, err := h.DB().GetInscriptionById(tables.StringToInscriptionId(inscription.Delegate))
+		if err != nil {
+			return err
+		}
+		if delegate.Id == 0 {
+			ctx.Status(http.StatusNotFound)
+			return nil
+		}
+		inscription.Body = delegate.Body
+		inscription.ContentType = delegate.ContentType
+		inscription.ContentEncoding = delegate.ContentEncoding
+	}
+
 	// Set cache control headers
 	ctx.Header(context.CacheControlHeaderKey, "public, max-age=1209600, immutable")
 
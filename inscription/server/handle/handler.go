@@ -7,6 +7,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/gin-gonic/gin"
 	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/inscription/index"
 	"github.com/inscription-c/cins/inscription/index/dao"
 	"github.com/inscription-c/cins/inscription/log"
 	"github.com/inscription-c/cins/pkg/signal"
@@ -19,8 +20,10 @@ type Options struct {
 	addr    string            // The address to bind the server to
 	testnet bool              // Whether to use the testnet or not
 	engin   *gin.Engine       // The gin engine for handling HTTP requests
-	db      *dao.DB           // The database for storing data
+	db      dao.Store         // The database for storing data
 	cli     *rpcclient.Client // The RPC client for interacting with the Bitcoin network
+	mempool *index.Mempool    // The mempool watcher, nil if mempool tracking is disabled
+	indexer *index.Indexer    // The indexer controlled by the admin API
 }
 
 // Option is a function type that sets a specific option in an Options struct.
@@ -43,8 +46,8 @@ func WithEngin(g *gin.Engine) func(*Options) {
 }
 
 // WithDB is a function that sets the database option for an Options struct.
-// It takes a pointer to a dao.DB representing the database and returns a function that sets the database option in the Options struct.
-func WithDB(db *dao.DB) func(*Options) {
+// It takes a dao.Store representing the database and returns a function that sets the database option in the Options struct.
+func WithDB(db dao.Store) func(*Options) {
 	return func(options *Options) {
 		options.db = db
 	}
@@ -66,13 +69,29 @@ func WithClient(cli *rpcclient.Client) func(*Options) {
 	}
 }
 
+// WithMempool is a function that sets the mempool watcher option for an Options struct.
+// It takes a pointer to an index.Mempool and returns a function that sets the mempool option in the Options struct.
+func WithMempool(mempool *index.Mempool) func(*Options) {
+	return func(options *Options) {
+		options.mempool = mempool
+	}
+}
+
+// WithIndexer is a function that sets the indexer option for an Options struct.
+// It takes a pointer to an index.Indexer and returns a function that sets the indexer option in the Options struct.
+func WithIndexer(indexer *index.Indexer) func(*Options) {
+	return func(options *Options) {
+		options.indexer = indexer
+	}
+}
+
 // Handler is a struct that holds the options for handling requests.
 type Handler struct {
 	options *Options
 }
 
 // DB is a method that returns the database from the options of a Handler.
-func (h *Handler) DB() *dao.DB {
+func (h *Handler) DB() dao.Store {
 	return h.options.db
 }
 
@@ -81,6 +100,16 @@ func (h *Handler) RpcClient() *rpcclient.Client {
 	return h.options.cli
 }
 
+// Mempool is a method that returns the mempool watcher from the options of a Handler.
+func (h *Handler) Mempool() *index.Mempool {
+	return h.options.mempool
+}
+
+// Indexer is a method that returns the indexer from the options of a Handler.
+func (h *Handler) Indexer() *index.Indexer {
+	return h.options.indexer
+}
+
 // Engine is a method that returns the gin engine from the options of a Handler.
 func (h *Handler) Engine() *gin.Engine {
 	return h.options.engin
@@ -23,6 +23,7 @@ type RespInscription struct {
 	Next            string                 `json:"next"`
 	Previous        string                 `json:"previous"`
 	Owner           string                 `json:"address"`
+	GenesisOwner    string                 `json:"genesis_address"`
 	Sat             uint64                 `json:"sat"`
 	ContentLength   int                    `json:"content_length"`
 	ContentType     string                 `json:"content_type"`
@@ -33,6 +34,9 @@ type RespInscription struct {
 	Timestamp       int64                  `json:"timestamp"`
 	CInsDescription tables.CInsDescription `json:"c_ins_description"`
 	ContentProtocol string                 `json:"content_protocol"`
+	Delegate        string                 `json:"delegate"`
+	Metaprotocol    string                 `json:"metaprotocol"`
+	Parents         []string               `json:"parents"`
 }
 
 // Inscription is a handler function for handling inscription requests.
@@ -124,14 +128,24 @@ func (h *Handler) doInscription(ctx *gin.Context, query string) error {
 		contentProtocol = constants.ProtocolCBRC20
 	}
 
+	parentIds, err := h.DB().FindParentsBySequenceNum(inscription.SequenceNum)
+	if err != nil {
+		return err
+	}
+	parents := make([]string, 0, len(parentIds))
+	for _, v := range parentIds {
+		parents = append(parents, v.String())
+	}
+
 	resp := &RespInscription{
-		InscriptionId:   inscriptionId.String(),
+		InscriptionId:   inscription.InscriptionId.String(),
 		InscriptionNum:  inscription.InscriptionNum,
 		Charms:          index.CharmsAll.Titles(inscription.Charms),
 		GenesisHeight:   inscription.Height,
 		GenesisFee:      inscription.Fee,
 		OutputValue:     value,
-		Owner:           inscription.Owner,
+		Owner:           inscription.CurrentOwner,
+		GenesisOwner:    inscription.Owner,
 		Sat:             inscription.Sat,
 		SatPoint:        satPointStr,
 		ContentType:     inscription.ContentType,
@@ -139,8 +153,11 @@ func (h *Handler) doInscription(ctx *gin.Context, query string) error {
 		Timestamp:       inscription.Timestamp,
 		CInsDescription: inscription.CInsDescription,
 		ContentProtocol: contentProtocol,
+		Delegate:        inscription.Delegate,
+		Metaprotocol:    inscription.Metaprotocol,
 		Previous:        preInscriptionId,
 		Next:            nextInscriptionId,
+		Parents:         parents,
 	}
 	ctx.JSON(http.StatusOK, resp)
 	return nil
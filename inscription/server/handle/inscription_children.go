@@ -0,0 +1,66 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"net/http"
+)
+
+// InscriptionChildren is a handler function for handling inscription children requests.
+// It validates the request parameters and calls the doInscriptionChildren function.
+func (h *Handler) InscriptionChildren(ctx *gin.Context) {
+	inscriptionId := tables.StringToInscriptionId(ctx.Param("query"))
+	if inscriptionId == nil {
+		ctx.String(http.StatusBadRequest, "invalid inscription id")
+		return
+	}
+	page := ctx.Param("page")
+	if page == "" {
+		page = "1"
+	}
+	if gconv.Int(page) < 1 {
+		ctx.String(http.StatusBadRequest, "invalid page")
+		return
+	}
+	if err := h.doInscriptionChildren(ctx, inscriptionId, gconv.Int(page)); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doInscriptionChildren is a helper function for handling inscription children requests.
+// It retrieves a page of the children of an inscription and returns them in the response.
+func (h *Handler) doInscriptionChildren(ctx *gin.Context, inscriptionId *tables.InscriptionId, page int) error {
+	inscription, err := h.DB().GetInscriptionById(inscriptionId)
+	if err != nil {
+		return err
+	}
+	if inscription.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	size := 100
+	list, err := h.DB().FindChildrenBySequenceNum(inscription.SequenceNum, page, size)
+	if err != nil {
+		return err
+	}
+	more := false
+	if len(list) > size {
+		more = true
+		list = list[:size]
+	}
+
+	children := make([]string, 0, len(list))
+	for _, v := range list {
+		children = append(children, v.String())
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"inscription_id": inscriptionId.String(),
+		"page_index":     page,
+		"more":           more,
+		"children":       children,
+	})
+	return nil
+}
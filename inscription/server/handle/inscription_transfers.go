@@ -0,0 +1,83 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"net/http"
+)
+
+// RespInscriptionTransfer is a struct that represents a location change in the transfer history of an inscription.
+type RespInscriptionTransfer struct {
+	TxId        string `json:"txid"`
+	Height      uint32 `json:"height"`
+	Timestamp   int64  `json:"timestamp"`
+	OldSatPoint string `json:"old_satpoint"`
+	NewSatPoint string `json:"new_satpoint"`
+	Owner       string `json:"owner"`
+}
+
+// InscriptionTransfers is a handler function for handling inscription transfer history requests.
+// It validates the request parameters and calls the doInscriptionTransfers function.
+func (h *Handler) InscriptionTransfers(ctx *gin.Context) {
+	inscriptionId := tables.StringToInscriptionId(ctx.Param("query"))
+	if inscriptionId == nil {
+		ctx.String(http.StatusBadRequest, "invalid inscription id")
+		return
+	}
+	page := ctx.Param("page")
+	if page == "" {
+		page = "1"
+	}
+	if gconv.Int(page) < 1 {
+		ctx.String(http.StatusBadRequest, "invalid page")
+		return
+	}
+	if err := h.doInscriptionTransfers(ctx, inscriptionId, gconv.Int(page)); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doInscriptionTransfers is a helper function for handling inscription transfer history requests.
+// It retrieves a page of the location changes of an inscription, oldest first, and returns them in the response.
+func (h *Handler) doInscriptionTransfers(ctx *gin.Context, inscriptionId *tables.InscriptionId, page int) error {
+	inscription, err := h.DB().GetInscriptionById(inscriptionId)
+	if err != nil {
+		return err
+	}
+	if inscription.Id == 0 {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+
+	size := 100
+	list, err := h.DB().FindTransfersBySequenceNum(inscription.SequenceNum, page, size)
+	if err != nil {
+		return err
+	}
+	more := false
+	if len(list) > size {
+		more = true
+		list = list[:size]
+	}
+
+	transfers := make([]*RespInscriptionTransfer, 0, len(list))
+	for _, v := range list {
+		transfers = append(transfers, &RespInscriptionTransfer{
+			TxId:        v.TxId,
+			Height:      v.Height,
+			Timestamp:   v.Timestamp,
+			OldSatPoint: v.OldSatPoint,
+			NewSatPoint: v.NewSatPoint,
+			Owner:       v.Owner,
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"inscription_id": inscriptionId.String(),
+		"page_index":     page,
+		"more":           more,
+		"transfers":      transfers,
+	})
+	return nil
+}
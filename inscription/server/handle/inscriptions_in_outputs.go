@@ -0,0 +1,57 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/constants"
+	"net/http"
+	"strings"
+)
+
+// maxOutputsQuery is the maximum number of outputs of an inscriptions in outputs request.
+const maxOutputsQuery = 1000
+
+// InscriptionsInOutputs is a handler function for handling inscriptions in outputs requests.
+// The request body is a JSON array of outpoints, the response maps every outpoint to the
+// ids of the inscriptions it currently holds.
+func (h *Handler) InscriptionsInOutputs(ctx *gin.Context) {
+	outputs := make([]string, 0)
+	if err := ctx.ShouldBindJSON(&outputs); err != nil {
+		ctx.String(http.StatusBadRequest, "invalid outputs")
+		return
+	}
+	if len(outputs) > maxOutputsQuery {
+		ctx.String(http.StatusBadRequest, "too many outputs")
+		return
+	}
+	for idx, output := range outputs {
+		output = strings.ToLower(strings.TrimSpace(output))
+		if !constants.OutpointRegexp.MatchString(output) {
+			ctx.String(http.StatusBadRequest, "invalid output: "+output)
+			return
+		}
+		outputs[idx] = output
+	}
+	if err := h.doInscriptionsInOutputs(ctx, outputs); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doInscriptionsInOutputs is a helper function for handling inscriptions in outputs requests.
+// It looks up the inscriptions of all outputs with a single query.
+func (h *Handler) doInscriptionsInOutputs(ctx *gin.Context, outputs []string) error {
+	list, err := h.DB().InscriptionsByOutpoints(outputs)
+	if err != nil {
+		return err
+	}
+
+	resp := make(map[string][]string, len(outputs))
+	for _, output := range outputs {
+		resp[output] = make([]string, 0)
+	}
+	for _, v := range list {
+		resp[v.Outpoint] = append(resp[v.Outpoint], v.InscriptionId.String())
+	}
+	ctx.JSON(http.StatusOK, resp)
+	return nil
+}
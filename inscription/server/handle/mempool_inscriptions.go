@@ -0,0 +1,20 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// MempoolInscriptions is a handler function for handling mempool inscriptions requests.
+// It returns the inscriptions revealed and the inscriptions transferred by unconfirmed transactions.
+func (h *Handler) MempoolInscriptions(ctx *gin.Context) {
+	if h.Mempool() == nil {
+		ctx.String(http.StatusNotFound, "mempool tracking is disabled")
+		return
+	}
+	inscriptions, transfers := h.Mempool().Pending()
+	ctx.JSON(http.StatusOK, gin.H{
+		"inscriptions": inscriptions,
+		"transfers":    transfers,
+	})
+}
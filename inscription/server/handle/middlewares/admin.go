@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// AdminAuth is a middleware that only lets requests carrying the admin token
+// in the Authorization header as a bearer token through.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if token == "" || subtle.ConstantTimeCompare([]byte(auth), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// ReorgEvents is a handler function for handling reorg event subscriptions.
+// It streams the reorgs handled by the indexer as server-sent events until the client disconnects.
+func (h *Handler) ReorgEvents(ctx *gin.Context) {
+	if h.Indexer() == nil {
+		ctx.String(http.StatusNotFound, "indexer is not running")
+		return
+	}
+	events, cancel := h.Indexer().SubscribeReorg()
+	defer cancel()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event := <-events:
+			ctx.SSEvent("reorg", event)
+			return true
+		}
+	})
+}
@@ -28,14 +28,32 @@ func (h *Handler) InitRouter() {
 
 	// inscriptions
 	h.Engine().GET("/inscription/:query", h.Inscription)
+	h.Engine().GET("/inscription/:query/children/:page", h.InscriptionChildren)
+	h.Engine().GET("/inscription/:query/transfers/:page", h.InscriptionTransfers)
 	h.Engine().GET("/content/:inscriptionId", h.Content)
 	h.Engine().GET("/inscriptions/:pages", h.Inscriptions)
 	h.Engine().GET("/inscriptions/block/:height/:page", h.InscriptionsInBlockPage)
 	h.Engine().GET("/output/:output", h.InscriptionsInOutput)
+	h.Engine().POST("/outputs", h.InscriptionsInOutputs)
+	h.Engine().GET("/search/inscriptions", h.SearchInscriptions)
+
+	// mempool
+	h.Engine().GET("/mempool/inscriptions", h.MempoolInscriptions)
+
+	// events
+	h.Engine().GET("/events/reorg", h.ReorgEvents)
+
+	// sat
+	h.Engine().GET("/sat/:sat", h.Sat)
+
+	// address
+	h.Engine().GET("/address/:address/inscriptions/:page", h.AddressInscriptions)
 
 	// cbrc20
 	h.Engine().GET("/cbrc20/token/:tkid", h.BRC20CToken)
 	h.Engine().GET("/cbrc20/tokens/:tk/:page", h.BRC20CTokens)
+	h.Engine().GET("/cbrc20/balance/:address", h.BRC20CBalance)
+	h.Engine().GET("/cbrc20/holders/:tkid/:page", h.BRC20CHolders)
 
 	// block
 	h.Engine().GET("/blockhash", h.BlockHash)
@@ -46,4 +64,13 @@ func (h *Handler) InitRouter() {
 
 	r := h.Engine().Group("/r")
 	r.GET("/blockheight", h.BlockHeight)
+
+	// admin, only served if an admin token is configured
+	if config.SrvCfg.Server.AdminToken != "" && h.Indexer() != nil {
+		admin := h.Engine().Group("/admin", middlewares.AdminAuth(config.SrvCfg.Server.AdminToken))
+		admin.GET("/indexer", h.IndexerStatus)
+		admin.POST("/indexer/pause", h.PauseIndexer)
+		admin.POST("/indexer/resume", h.ResumeIndexer)
+		admin.POST("/indexer/stop/:height", h.StopIndexerAt)
+	}
 }
@@ -0,0 +1,91 @@
+package handle
+
+import (
+	"github.com/btcsuite/btcd/wire"
+	"github.com/gin-gonic/gin"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"net/http"
+	"strings"
+)
+
+// RespSat is a struct that represents the response for a sat request.
+type RespSat struct {
+	Sat          uint64   `json:"sat"`
+	Decimal      string   `json:"decimal"`
+	Degree       string   `json:"degree"`
+	Percentile   string   `json:"percentile"`
+	Name         string   `json:"name"`
+	Rarity       string   `json:"rarity"`
+	Charms       []string `json:"charms"`
+	Block        uint32   `json:"block"`
+	Epoch        uint32   `json:"epoch"`
+	Offset       uint64   `json:"offset"`
+	Inscriptions []string `json:"inscriptions"`
+	SatPoint     string   `json:"satpoint"`
+}
+
+// Sat is a handler function for handling sat requests.
+// It validates the request parameters and calls the doSat function.
+func (h *Handler) Sat(ctx *gin.Context) {
+	sat, err := index.ParseSat(strings.TrimSpace(ctx.Param("sat")))
+	if err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.doSat(ctx, sat); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doSat is a helper function for handling sat requests.
+// It returns the properties of a sat, the inscriptions on it and its current satpoint.
+// The satpoint is only known if the sat is rare or inscribed and the index tracks sats.
+func (h *Handler) doSat(ctx *gin.Context, sat index.Sat) error {
+	list, err := h.DB().FindInscriptionsBySat(sat.N())
+	if err != nil {
+		return err
+	}
+	inscriptions := make([]string, 0, len(list))
+	for _, v := range list {
+		inscriptions = append(inscriptions, v.InscriptionId.String())
+	}
+
+	satPoint := ""
+	satSatPoint, err := h.DB().GetSatPointBySat(sat.N())
+	if err != nil {
+		return err
+	}
+	if satSatPoint.Id > 0 {
+		satPoint = tables.FormatSatPoint(satSatPoint.Outpoint, satSatPoint.Offset)
+	} else if len(list) > 0 {
+		inscriptionSatPoint, err := h.DB().GetSatPointBySequenceNum(list[len(list)-1].SequenceNum)
+		if err != nil {
+			return err
+		}
+		if inscriptionSatPoint.Id > 0 {
+			outpoint := inscriptionSatPoint.Outpoint
+			if outpoint == "" {
+				outpoint = wire.OutPoint{}.String()
+			}
+			satPoint = tables.FormatSatPoint(outpoint, inscriptionSatPoint.Offset)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, &RespSat{
+		Sat:          sat.N(),
+		Decimal:      sat.Decimal(),
+		Degree:       sat.Degree().String(),
+		Percentile:   sat.Percentile(),
+		Name:         sat.Name(),
+		Rarity:       sat.Rarity().String(),
+		Charms:       index.CharmsAll.Titles(sat.Charms()),
+		Block:        sat.Height().N(),
+		Epoch:        sat.Epoch().N(),
+		Offset:       sat.Third(),
+		Inscriptions: inscriptions,
+		SatPoint:     satPoint,
+	})
+	return nil
+}
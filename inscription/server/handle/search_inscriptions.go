@@ -0,0 +1,98 @@
+package handle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/inscription-c/cins/inscription/index/dao"
+	"net/http"
+	"strings"
+)
+
+// SearchInscriptions is a handler function for handling inscription search requests.
+// It validates the query parameters and calls the doSearchInscriptions function.
+// Supported query parameters are owner (current owner), genesis_owner, ticker, type, metaprotocol, order (newest or oldest),
+// media_type, content_type and charm (repeatable or comma separated), page and limit.
+func (h *Handler) SearchInscriptions(ctx *gin.Context) {
+	page := gconv.Int(ctx.DefaultQuery("page", "1"))
+	if page < 1 {
+		ctx.String(http.StatusBadRequest, "invalid page")
+		return
+	}
+	limit := gconv.Int(ctx.DefaultQuery("limit", "100"))
+	if limit < 1 || limit > 100 {
+		ctx.String(http.StatusBadRequest, "invalid limit")
+		return
+	}
+	order := ctx.Query("order")
+	if order != "" && order != "newest" && order != "oldest" {
+		ctx.String(http.StatusBadRequest, "invalid order")
+		return
+	}
+
+	var charms uint16
+	for _, title := range queryList(ctx, "charm") {
+		charm := index.TitleToCharm(title)
+		if charm == nil {
+			ctx.String(http.StatusBadRequest, "invalid charm: "+title)
+			return
+		}
+		charm.Set(&charms)
+	}
+
+	params := &dao.FindProtocolsParams{
+		Page:            page,
+		Limit:           limit,
+		Owner:           strings.TrimSpace(ctx.Query("owner")),
+		GenesisOwner:    strings.TrimSpace(ctx.Query("genesis_owner")),
+		Ticker:          strings.TrimSpace(ctx.Query("ticker")),
+		Order:           order,
+		MediaTypes:      queryList(ctx, "media_type"),
+		ContentTypes:    queryList(ctx, "content_type"),
+		Charms:          charms,
+		InscriptionType: strings.TrimSpace(ctx.Query("type")),
+		Metaprotocol:    strings.TrimSpace(ctx.Query("metaprotocol")),
+	}
+	if err := h.doSearchInscriptions(ctx, params); err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// doSearchInscriptions is a helper function for handling inscription search requests.
+// It retrieves a page of the matched inscriptions and returns their IDs with the total count.
+func (h *Handler) doSearchInscriptions(ctx *gin.Context, params *dao.FindProtocolsParams) error {
+	list, total, err := h.DB().SearchInscriptions(params)
+	if err != nil {
+		return err
+	}
+
+	inscriptions := make([]string, 0, len(list))
+	for _, v := range list {
+		inscriptions = append(inscriptions, v.InscriptionId.String())
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"page_index":   params.Page,
+		"page_size":    params.Limit,
+		"total":        total,
+		"more":         int64(params.Page*params.Limit) < total,
+		"inscriptions": inscriptions,
+	})
+	return nil
+}
+
+// queryList returns the values of a repeatable query parameter,
+// splitting comma separated values and dropping empty ones.
+func queryList(ctx *gin.Context, key string) []string {
+	list := make([]string, 0)
+	for _, v := range ctx.QueryArray(key) {
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			list = append(list, item)
+		}
+	}
+	return list
+}
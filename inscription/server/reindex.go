@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// ReindexCmd re-indexes a range of blocks without wiping the database.
+// The indexer must be stopped while it runs.
+var ReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "re-index a range of blocks, the indexer must be stopped",
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := reindexRange()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+var (
+	reindexFrom   uint32
+	reindexTo     uint32
+	reindexDryRun bool
+)
+
+func init() {
+	ReindexCmd.Flags().Uint32VarP(&reindexFrom, "from", "", 0, "first block to re-index")
+	ReindexCmd.Flags().Uint32VarP(&reindexTo, "to", "", 0, "last block to re-index (default the index height)")
+	ReindexCmd.Flags().BoolVarP(&reindexDryRun, "dry_run", "", false, "re-index without writing and print the rows that differ from the index")
+	_ = ReindexCmd.MarkFlagRequired("from")
+	Cmd.AddCommand(ReindexCmd)
+}
+
+func reindexRange() (*index.ReindexReport, error) {
+	if err := loadConfig(); err != nil {
+		return nil, err
+	}
+	initLogRotator()
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	indexer, err := newIndexer(db)
+	if err != nil {
+		return nil, err
+	}
+	to := reindexTo
+	if to == 0 {
+		if to, err = db.BlockHeight(); err != nil {
+			return nil, err
+		}
+	}
+	return indexer.ReindexRange(reindexFrom, to, reindexDryRun)
+}
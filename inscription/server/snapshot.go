@@ -0,0 +1,127 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+// ExportCmd dumps the index at the last committed block into a snapshot file.
+var ExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "export the index into a snapshot file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := exportSnapshot(args[0]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// ImportCmd restores a snapshot file into an empty database.
+var ImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "import a snapshot file into an empty database",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := importSnapshot(args[0]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+}
+
+// snapshotBlockHash is the trusted block hash the imported snapshot must have.
+var snapshotBlockHash string
+
+func init() {
+	ImportCmd.Flags().StringVarP(&snapshotBlockHash, "block_hash", "", "", "expected hash of the last block of the snapshot, not checked if empty")
+	Cmd.AddCommand(ExportCmd, ImportCmd)
+}
+
+// exportSnapshot writes the snapshot to a temporary file first, so an interrupted export leaves no partial snapshot.
+func exportSnapshot(path string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	initLogRotator()
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	header, err := index.ExportSnapshot(db, w)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return printSnapshotHeader(header)
+}
+
+// importSnapshot verifies the snapshot before importing it.
+func importSnapshot(path string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	initLogRotator()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	header, err := index.ReadSnapshot(bufio.NewReader(file), nil)
+	if err != nil {
+		return err
+	}
+	if snapshotBlockHash != "" && header.BlockHash != snapshotBlockHash {
+		return fmt.Errorf("snapshot block hash %s at height %d, expected %s", header.BlockHash, header.Height, snapshotBlockHash)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := index.ImportSnapshot(db, bufio.NewReader(file)); err != nil {
+		if errors.Is(err, index.ErrSnapshotChecksum) {
+			return fmt.Errorf("%w, the snapshot changed while importing, drop the imported tables and retry", err)
+		}
+		return err
+	}
+	return printSnapshotHeader(header)
+}
+
+func printSnapshotHeader(header *index.SnapshotHeader) error {
+	data, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -113,6 +113,22 @@ func WithMysqlDBName(mysqlDBName string) SrvOption {
 	}
 }
 
+// WithDBBackend is a function that returns a SrvOption.
+// The returned SrvOption sets the backend field of the config.SrvConfigs struct to the provided backend.
+func WithDBBackend(backend string) SrvOption {
+	return func(options *config.SrvConfigs) {
+		options.DB.Backend = backend
+	}
+}
+
+// WithSqlitePath is a function that returns a SrvOption.
+// The returned SrvOption sets the sqlite path field of the config.SrvConfigs struct to the provided path.
+func WithSqlitePath(path string) SrvOption {
+	return func(options *config.SrvConfigs) {
+		options.DB.Sqlite.Path = path
+	}
+}
+
 // WithEnablePProf is a function that returns a SrvOption.
 // The returned SrvOption sets the enablePProf field of the config.SrvConfigs struct to the provided enablePProf.
 func WithEnablePProf(enablePProf bool) SrvOption {
@@ -136,20 +152,30 @@ var Cmd = &cobra.Command{
 var configFilePath string
 
 func init() {
-	Cmd.Flags().StringVarP(&configFilePath, "config", "c", "", "config file path")
-	Cmd.Flags().BoolVarP(&config.SrvCfg.Server.Testnet, "testnet", "t", false, "bitcoin testnet3")
+	Cmd.PersistentFlags().StringVarP(&configFilePath, "config", "c", "", "config file path")
+	Cmd.PersistentFlags().BoolVarP(&config.SrvCfg.Server.Testnet, "testnet", "t", false, "bitcoin testnet3")
 	Cmd.Flags().StringVarP(&config.SrvCfg.Server.RpcListen, "rpc_listen", "l", "", "rpc server listen address. Default `mainnet :8335, testnet :18335`")
-	Cmd.Flags().StringVarP(&config.SrvCfg.Chain.Url, "chain_url", "s", "", "the bitcoin backend URL of RPC server to connect to (default http://localhost:8334, testnet: http://localhost:18334)")
-	Cmd.Flags().StringVarP(&config.SrvCfg.Chain.Username, "chain_user", "u", "root", "bitcoin rpc server username")
-	Cmd.Flags().StringVarP(&config.SrvCfg.Chain.Password, "chain_password", "P", "root", "bitcoin rpc server password")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.Chain.Url, "chain_url", "s", "", "the bitcoin backend URL of RPC server to connect to (default http://localhost:8334, testnet: http://localhost:18334)")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.Chain.Username, "chain_user", "u", "root", "bitcoin rpc server username")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.Chain.Password, "chain_password", "P", "root", "bitcoin rpc server password")
+	Cmd.Flags().StringVarP(&config.SrvCfg.Chain.BlockNotify, "block_notify", "", "", "subscribe to new blocks, zmq (bitcoind) or websocket (btcd), polls the node if empty")
+	Cmd.Flags().StringVarP(&config.SrvCfg.Chain.ZmqBlock, "zmq_block", "", "", "bitcoind zmq rawblock publisher address, discovered with getzmqnotifications if empty")
+	Cmd.PersistentFlags().Uint32VarP(&config.SrvCfg.Reorg.MaxSavepoint, "max_savepoint", "", 2, "number of savepoints kept for reorg recovery")
+	Cmd.PersistentFlags().Uint32VarP(&config.SrvCfg.Reorg.SavepointInterval, "savepoint_interval", "", 10, "number of blocks between savepoints, reorgs up to (max_savepoint-1)*savepoint_interval blocks deep are rolled back")
+	Cmd.PersistentFlags().Uint32VarP(&config.SrvCfg.Reorg.ChainTipDistance, "chain_tip_distance", "", 21, "distance to the chain tip within which savepoints are created")
+	Cmd.Flags().Int64VarP(&config.SrvCfg.Reorg.ReindexHeight, "reorg_reindex_height", "", -1, "roll the index back and re-index from this height after an unrecoverable reorg, stop indexing if 0 or negative")
 	Cmd.Flags().BoolVarP(&config.SrvCfg.Server.NoApi, "no_api", "", false, "don't start api server")
-	Cmd.Flags().StringVarP(&config.SrvCfg.DB.Mysql.Addr, "mysql_addr", "d", "", "inscription index mysql database addr")
-	Cmd.Flags().StringVarP(&config.SrvCfg.DB.Mysql.User, "mysql_user", "", "root", "inscription index mysql database user")
-	Cmd.Flags().StringVarP(&config.SrvCfg.DB.Mysql.Password, "mysql_pass", "", "root", "inscription index mysql database password")
-	Cmd.Flags().StringVarP(&config.SrvCfg.DB.Mysql.DB, "db", "", "", "inscription index mysql database name")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.DB.Backend, "db_backend", "", dao.BackendMysql, "inscription index database backend, mysql or sqlite")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.DB.Sqlite.Path, "sqlite_path", "", "", "inscription index sqlite database file (default in the app data dir)")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.DB.Mysql.Addr, "mysql_addr", "d", "", "inscription index mysql database addr")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.DB.Mysql.User, "mysql_user", "", "root", "inscription index mysql database user")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.DB.Mysql.Password, "mysql_pass", "", "root", "inscription index mysql database password")
+	Cmd.PersistentFlags().StringVarP(&config.SrvCfg.DB.Mysql.DB, "db", "", "", "inscription index mysql database name")
 	Cmd.Flags().BoolVarP(&config.SrvCfg.Server.EnablePProf, "pprof", "", false, "enable pprof")
 	Cmd.Flags().StringVarP(&config.SrvCfg.Server.IndexSats, "index_sats", "", "", "Track location of all satoshis, true/false")
 	Cmd.Flags().StringVarP(&config.SrvCfg.Server.IndexSpendSats, "index_spend_sats", "", "", "Keep sat index entries of spent outputs, true/false")
+	Cmd.Flags().BoolVarP(&config.SrvCfg.Server.Mempool, "mempool", "", false, "track pending inscriptions and transfers in the mempool")
+	Cmd.Flags().StringVarP(&config.SrvCfg.Server.AdminToken, "admin_token", "", "", "bearer token of the admin api to pause, resume or stop the indexer at a height, disabled if empty")
 	Cmd.Flags().StringVarP(&config.SrvCfg.Sentry.Dsn, "sentry_dsn", "", "", "sentry dsn")
 	Cmd.Flags().Float64VarP(&config.SrvCfg.Sentry.TracesSampleRate, "sentry_traces_sample_rate", "", 1.0, "sentry traces sample rate")
 	Cmd.Flags().BoolVarP(&config.SrvCfg.Server.Prometheus, "prometheus", "", false, "enable prometheus metrics")
@@ -157,6 +183,75 @@ func init() {
 }
 
 func IndexSrv(opts ...SrvOption) error {
+	if err := loadConfig(opts...); err != nil {
+		return err
+	}
+	initLogRotator()
+
+	// Initialize sentry error reporting.
+	if config.SrvCfg.Sentry.Dsn != "" {
+		if err := sentry.Init(sentry.ClientOptions{
+			EnableTracing:    true,
+			Dsn:              config.SrvCfg.Sentry.Dsn,
+			TracesSampleRate: config.SrvCfg.Sentry.TracesSampleRate,
+		}); err != nil {
+			return err
+		}
+		defer sentry2.RecoverPanic()
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	indexer, err := newIndexer(db)
+	if err != nil {
+		return err
+	}
+	cli := indexer.RpcClient()
+
+	// Start the indexer.
+	indexer.Start()
+	// Add an interrupt handler that stops the indexer when an interrupt signal is received.
+	// The block being indexed is finished and committed before the handler returns.
+	signal.AddInterruptHandler(func() {
+		indexer.Stop()
+	})
+
+	// Start watching the mempool for pending inscriptions and transfers if enabled.
+	var mempool *index.Mempool
+	if config.SrvCfg.Server.Mempool {
+		mempool = index.NewMempool(db, cli)
+		mempool.Start()
+		signal.AddInterruptHandler(mempool.Stop)
+	}
+	// If the no API field of the server options is false, create and run a new handler.
+	if !config.SrvCfg.Server.NoApi {
+		// Create a new handler using the database, the client, the RPC listen, the testnet,
+		//and to enable pprof from the server options.
+		h, err := handle.New(
+			handle.WithDB(db),
+			handle.WithClient(cli),
+			handle.WithAddr(config.SrvCfg.Server.RpcListen),
+			handle.WithTestNet(config.SrvCfg.Server.Testnet),
+			handle.WithMempool(mempool),
+			handle.WithIndexer(indexer),
+		)
+		if err != nil {
+			return err
+		}
+		// Run the handler.
+		// If there is an error running the handler, return the error.
+		if err := h.Run(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfig loads the config file, applies the options and fills in the defaults of config.SrvCfg.
+func loadConfig(opts ...SrvOption) error {
 	if configFilePath != "" {
 		configFile, err := os.Open(configFilePath)
 		if err != nil {
@@ -172,6 +267,12 @@ func IndexSrv(opts ...SrvOption) error {
 		v(config.SrvCfg)
 	}
 
+	if config.SrvCfg.DB.Backend == "" {
+		config.SrvCfg.DB.Backend = dao.BackendMysql
+	}
+	if config.SrvCfg.DB.Sqlite.Path == "" {
+		config.SrvCfg.DB.Sqlite.Path = filepath.Join(constants.DBDatDir(config.SrvCfg.Server.Testnet), constants.DefaultDBName+".db")
+	}
 	if config.SrvCfg.DB.Mysql.DB == "" {
 		config.SrvCfg.DB.Mysql.DB = constants.DefaultDBName
 	}
@@ -194,102 +295,66 @@ func IndexSrv(opts ...SrvOption) error {
 			config.SrvCfg.Chain.Url = mainNetRPCConnect
 		}
 	}
+	return nil
+}
 
-	// Initialize log rotation.  After log rotation has been initialized, the
-	// logger variables may be used.
+// initLogRotator initializes log rotation.  After log rotation has been initialized, the
+// logger variables may be used.
+func initLogRotator() {
 	logDir := filepath.Join(constants.AppName, "inscription", "logs", "index.log")
 	logFile := btcutil.AppDataDir(logDir, false)
 	log.InitLogRotator(logFile)
+}
 
-	// Initialize sentry error reporting.
-	if config.SrvCfg.Sentry.Dsn != "" {
-		if err := sentry.Init(sentry.ClientOptions{
-			EnableTracing:    true,
-			Dsn:              config.SrvCfg.Sentry.Dsn,
-			TracesSampleRate: config.SrvCfg.Sentry.TracesSampleRate,
-		}); err != nil {
-			return err
-		}
-		defer sentry2.RecoverPanic()
-	}
-
-	// Create a new database instance using the server options.
-	// The database is configured with the MySQL address, user, password, and database name from the server options.
-	// The data directory and embedded database flag from the server options are also used.
-	// The server port and status port for the database are set from the server options.
-	// The tables to auto-migrate in the database are set to the tables from the tables package.
-	db, err := dao.NewDB(
+// openDB creates a new database instance using the server options.
+// The database is configured with the backend, the SQLite path and the MySQL address, user, password,
+// and database name from the server options, only the settings of the chosen backend are used.
+// The tables to auto-migrate in the database are set to the tables from the tables package.
+func openDB() (*dao.DB, error) {
+	return dao.NewDB(
+		dao.WithBackend(config.SrvCfg.DB.Backend),
+		dao.WithPath(config.SrvCfg.DB.Sqlite.Path),
 		dao.WithAddr(config.SrvCfg.DB.Mysql.Addr),
 		dao.WithUser(config.SrvCfg.DB.Mysql.User),
 		dao.WithPassword(config.SrvCfg.DB.Mysql.Password),
 		dao.WithDBName(config.SrvCfg.DB.Mysql.DB),
 		dao.WithAutoMigrateTables(tables.Tables...),
 	)
-	if err != nil {
-		return err
-	}
+}
 
-	// Create a new RPC client using the server options.
-	// The client is configured with the RPC connect, username, and password from the server options.
-	cli, err := rpcclient.NewClient(
+// newRpcClient creates a new RPC client using the server options.
+// The client is configured with the RPC connect, username, and password from the server options.
+// A batch client operates in batch mode.
+func newRpcClient(batch bool) (*rpcclient.Client, error) {
+	return rpcclient.NewClient(
 		rpcclient.WithClientHost(config.SrvCfg.Chain.Url),
 		rpcclient.WithClientUser(config.SrvCfg.Chain.Username),
 		rpcclient.WithClientPassword(config.SrvCfg.Chain.Password),
+		rpcclient.WithClientBatch(batch),
 	)
+}
+
+// newIndexer creates a new indexer using the database, the client, the batch client, the index sats and the index spend sats.
+// The indexer is also configured with the block notifications and the reorg protection from the server options.
+func newIndexer(db *dao.DB) (*index.Indexer, error) {
+	cli, err := newRpcClient(false)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Create a new batch RPC client using the server options.
-	// The batch client is configured with the RPC connect, username, and password from the server options.
-	// The batch client is also set to operate in batch mode.
-	batchCli, err := rpcclient.NewClient(
-		rpcclient.WithClientHost(config.SrvCfg.Chain.Url),
-		rpcclient.WithClientUser(config.SrvCfg.Chain.Username),
-		rpcclient.WithClientPassword(config.SrvCfg.Chain.Password),
-		rpcclient.WithClientBatch(true),
-	)
+	batchCli, err := newRpcClient(true)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Create a new indexer using the database, the client, the batch client, the index sats, the index spend sats, and the TiDB session memory limit.
-	// The indexer is configured with the database, the client, and the batch client.
-	// The indexer is also configured with the index sats and index spend sats from the server options.
-	// The TiDB session memory limit is set to the TiDB session memory limit constant from the constants package.
-	indexer := index.NewIndexer(
+	return index.NewIndexer(
 		index.WithDB(db),
 		index.WithClient(cli),
 		index.WithBatchClient(batchCli),
 		index.WithIndexSats(config.SrvCfg.Server.IndexSats),
 		index.WithIndexSpendSats(config.SrvCfg.Server.IndexSpendSats),
-		index.WithTidbSessionMemLimit(constants.TidbSessionMemLimit),
-	)
-	// Start the indexer.
-	indexer.Start()
-	// Add an interrupt handler that stops the indexer when an interrupt signal is received.
-	signal.AddInterruptHandler(func() {
-		indexer.Stop()
-	})
-
-	// If the no API field of the server options is false, create and run a new handler.
-	if !config.SrvCfg.Server.NoApi {
-		// Create a new handler using the database, the client, the RPC listen, the testnet,
-		//and to enable pprof from the server options.
-		h, err := handle.New(
-			handle.WithDB(db),
-			handle.WithClient(cli),
-			handle.WithAddr(config.SrvCfg.Server.RpcListen),
-			handle.WithTestNet(config.SrvCfg.Server.Testnet),
-		)
-		if err != nil {
-			return err
-		}
-		// Run the handler.
-		// If there is an error running the handler, return the error.
-		if err := h.Run(); err != nil {
-			return err
-		}
-	}
-	return nil
+		index.WithBlockNotify(config.SrvCfg.Chain.BlockNotify),
+		index.WithZmqBlockHost(config.SrvCfg.Chain.ZmqBlock),
+		index.WithChain(config.SrvCfg.Chain.Url, config.SrvCfg.Chain.Username, config.SrvCfg.Chain.Password),
+		index.WithReorgProtection(config.SrvCfg.Reorg.MaxSavepoint, config.SrvCfg.Reorg.SavepointInterval, config.SrvCfg.Reorg.ChainTipDistance),
+		index.WithReorgReindexHeight(config.SrvCfg.Reorg.ReindexHeight),
+	), nil
 }
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/inscription-c/cins/inscription/index"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// VerifyCmd checks the index for consistency and prints the problems found as JSON.
+// It exits with status 2 if problems are found.
+var VerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "check the index for consistency",
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := verifyIndex()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		if len(report.Problems) > 0 {
+			os.Exit(2)
+		}
+	},
+}
+
+// verifyChecks are the checks run by the verify command.
+var verifyChecks []string
+
+func init() {
+	VerifyCmd.Flags().StringSliceVarP(&verifyChecks, "checks", "", index.VerifyChecks, "checks to run, block_chain, sat_point, sequence_num and statistic")
+	Cmd.AddCommand(VerifyCmd)
+}
+
+func verifyIndex() (*index.VerifyReport, error) {
+	if err := loadConfig(); err != nil {
+		return nil, err
+	}
+	initLogRotator()
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	cli, err := newRpcClient(false)
+	if err != nil {
+		return nil, err
+	}
+	return index.Verify(db, cli, verifyChecks...)
+}
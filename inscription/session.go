@@ -0,0 +1,442 @@
+package inscription
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/btcd/rpcclient"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/log"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SessionStatusUnsigned is the status of a session whose commit transaction waits for an external signer.
+	SessionStatusUnsigned = "unsigned"
+	// SessionStatusSigned is the status of a session whose transactions are signed and not sent.
+	SessionStatusSigned = "signed"
+	// SessionStatusCommitSent is the status of a session whose commit transaction is sent.
+	SessionStatusCommitSent = "commit_sent"
+	// SessionStatusRevealSent is the status of a session whose reveal transactions are sent.
+	SessionStatusRevealSent = "reveal_sent"
+	// SessionStatusRecovered is the status of a session whose commit outputs are swept back to the wallet.
+	SessionStatusRecovered = "recovered"
+)
+
+// ErrSessionNotFound is returned when a session is not in the journal.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is the persisted state of an inscribe, from the built transactions until the
+// reveal transactions are sent. It holds everything needed to rebroadcast the reveal
+// transactions or to sweep the commit outputs back to the wallet.
+type Session struct {
+	// Id is the id of the session, the id of the commit transaction.
+	Id string `json:"id"`
+
+	// Network is the name of the bitcoin network of the session.
+	Network string `json:"network"`
+
+	// Status is the status of the session.
+	Status string `json:"status"`
+
+	// PrivateKey is the temporary private key of the reveal scripts in wallet import format.
+	PrivateKey string `json:"private_key"`
+
+	// NoBackup is set if the temporary key is not backed up, it is only saved until the
+	// reveal transactions are signed, so the commit outputs of the session can not be recovered.
+	NoBackup bool `json:"no_backup,omitempty"`
+
+	// CommitTx is the signed commit transaction in hex, unsigned until the session is finalized.
+	CommitTx string `json:"commit_tx"`
+
+	// Psbt is the base64 PSBT of the unsigned commit transaction, for the external signer.
+	Psbt string `json:"psbt,omitempty"`
+
+	// RevealTxs are the signed reveal transactions in hex.
+	RevealTxs []string `json:"reveal_txs"`
+
+	// Reveals are the commit outputs spent by the reveal transactions.
+	Reveals []*SessionReveal `json:"reveals"`
+
+	// Inscriptions are the ids of the inscriptions of the session.
+	Inscriptions []string `json:"inscriptions"`
+
+	// RecoveryTx is the id of the transaction sweeping the commit outputs back to the wallet.
+	RecoveryTx string `json:"recovery_tx,omitempty"`
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// SessionReveal is a commit output of a session with the reveal script it commits to.
+type SessionReveal struct {
+	// Vout is the index of the output in the commit transaction.
+	Vout uint32 `json:"vout"`
+
+	// Script is the reveal script in hex.
+	Script string `json:"script"`
+
+	// ControlBlock is the control block of the reveal script in hex.
+	ControlBlock string `json:"control_block"`
+}
+
+// newSession is a function that creates a session of signed commit and reveal transactions.
+func newSession(priKey *btcec.PrivateKey, commitTx *wire.MsgTx, revealTxs []*wire.MsgTx, reveals []*SessionReveal, inscriptions []string) (*Session, error) {
+	wif, err := btcutil.NewWIF(priKey, util.ActiveNet.Params, true)
+	if err != nil {
+		return nil, err
+	}
+	commitTxHex, err := serializeTx(commitTx)
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{
+		Id:           commitTx.TxHash().String(),
+		Network:      util.ActiveNet.Params.Name,
+		Status:       SessionStatusSigned,
+		PrivateKey:   wif.String(),
+		NoBackup:     noBackup,
+		CommitTx:     commitTxHex,
+		Reveals:      reveals,
+		Inscriptions: inscriptions,
+		CreatedAt:    time.Now().Unix(),
+	}
+	for _, revealTx := range revealTxs {
+		revealTxHex, err := serializeTx(revealTx)
+		if err != nil {
+			return nil, err
+		}
+		session.RevealTxs = append(session.RevealTxs, revealTxHex)
+	}
+	return session, nil
+}
+
+// newSessionReveal is a function that creates the session reveal of a commit output.
+func newSessionReveal(vout int, revealScript []byte, controlBlock []byte) *SessionReveal {
+	return &SessionReveal{
+		Vout:         uint32(vout),
+		Script:       hex.EncodeToString(revealScript),
+		ControlBlock: hex.EncodeToString(controlBlock),
+	}
+}
+
+// PriKey is a method of the Session struct. It returns the temporary private key of the session.
+func (s *Session) PriKey() (*btcec.PrivateKey, error) {
+	if s.PrivateKey == "" {
+		return nil, fmt.Errorf("session %s has no private key, it is not backed up", s.Id)
+	}
+	wif, err := btcutil.DecodeWIF(s.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return wif.PrivKey, nil
+}
+
+// Commit is a method of the Session struct. It returns the commit transaction of the session.
+func (s *Session) Commit() (*wire.MsgTx, error) {
+	return deserializeTx(s.CommitTx)
+}
+
+// Reveal is a method of the Session struct. It returns the reveal transactions of the session.
+func (s *Session) Reveal() ([]*wire.MsgTx, error) {
+	revealTxs := make([]*wire.MsgTx, 0, len(s.RevealTxs))
+	for _, v := range s.RevealTxs {
+		revealTx, err := deserializeTx(v)
+		if err != nil {
+			return nil, err
+		}
+		revealTxs = append(revealTxs, revealTx)
+	}
+	return revealTxs, nil
+}
+
+// Done is a method of the Session struct. It returns whether nothing is left to send.
+func (s *Session) Done() bool {
+	return s.Status == SessionStatusRevealSent || s.Status == SessionStatusRecovered
+}
+
+// SessionJournal is a directory of session files, one JSON file per session.
+type SessionJournal struct {
+	dir string
+}
+
+// NewSessionJournal is a function that creates a session journal in a directory.
+func NewSessionJournal(dir string) (*SessionJournal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &SessionJournal{dir: dir}, nil
+}
+
+// defaultSessionDir is a function that returns the session directory of the active network in the app data dir.
+func defaultSessionDir() string {
+	return btcutil.AppDataDir(filepath.Join(constants.AppName, "inscription", "sessions", util.ActiveNet.Params.Name), false)
+}
+
+// Save is a method of the SessionJournal struct. It writes a session to a temporary file
+// and renames it to the session file, so that a crash never leaves a partial session.
+// The private key of a session which is not backed up is left out once its reveal transactions are signed.
+func (j *SessionJournal) Save(session *Session) error {
+	session.UpdatedAt = time.Now().Unix()
+	saved := *session
+	if saved.NoBackup && saved.Status != SessionStatusUnsigned {
+		saved.PrivateKey = ""
+	}
+	data, err := json.MarshalIndent(&saved, "", "\t")
+	if err != nil {
+		return err
+	}
+	file := j.path(session.Id)
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+// Load is a method of the SessionJournal struct. It reads a session by its id,
+// and returns ErrSessionNotFound if there is none.
+func (j *SessionJournal) Load(id string) (*Session, error) {
+	data, err := os.ReadFile(j.path(strings.ToLower(strings.TrimSpace(id))))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// List is a method of the SessionJournal struct. It returns all the sessions, oldest first.
+func (j *SessionJournal) List() ([]*Session, error) {
+	files, err := filepath.Glob(filepath.Join(j.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*Session, 0, len(files))
+	for _, file := range files {
+		session, err := j.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	sort.SliceStable(sessions, func(i, k int) bool {
+		return sessions[i].CreatedAt < sessions[k].CreatedAt
+	})
+	return sessions, nil
+}
+
+// Delete is a method of the SessionJournal struct. It removes a session by its id.
+func (j *SessionJournal) Delete(id string) error {
+	err := os.Remove(j.path(strings.ToLower(strings.TrimSpace(id))))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path is a method of the SessionJournal struct. It returns the file of a session.
+func (j *SessionJournal) path(id string) string {
+	return filepath.Join(j.dir, id+".json")
+}
+
+// sendSession is a function that sends the transactions of a session which are not sent yet,
+// the commit transaction first, and saves the status of the session after every step.
+// Transactions already known to the node are not an error, so a session can be resumed.
+func sendSession(walletCli *rpcclient.Client, journal *SessionJournal, session *Session) error {
+	if session.Status == SessionStatusSigned {
+		commitTx, err := session.Commit()
+		if err != nil {
+			return err
+		}
+		commitTxHash, err := walletCli.SendRawTransaction(commitTx, false)
+		if err != nil && !isTxKnownErr(err) {
+			return err
+		}
+		log.Log.Info("commitTxSendSuccess", commitTxHash)
+		session.Status = SessionStatusCommitSent
+		if err := journal.Save(session); err != nil {
+			return err
+		}
+	}
+
+	if session.Status == SessionStatusCommitSent {
+		revealTxs, err := session.Reveal()
+		if err != nil {
+			return err
+		}
+		for _, revealTx := range revealTxs {
+			revealTxHash, err := walletCli.SendRawTransaction(revealTx, false)
+			if err != nil && !isTxKnownErr(err) {
+				return err
+			}
+			log.Log.Info("revealTxSendSuccess", revealTxHash)
+		}
+		session.Status = SessionStatusRevealSent
+		if err := journal.Save(session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverSession is a function that sweeps the commit outputs of a session to a new change
+// address of the wallet at the fee rate of the fee estimator. It saves the session as
+// recovered and returns the sweep transaction.
+// A session whose reveal transactions are sent is only recovered if force is set,
+// as the sweep double-spends the reveal transactions and may replace them in the mempool.
+func recoverSession(walletCli *rpcclient.Client, journal *SessionJournal, session *Session, feeEstimator FeeEstimator, force bool) (*wire.MsgTx, error) {
+	if session.Status == SessionStatusRecovered {
+		return nil, fmt.Errorf("session %s is already recovered by %s", session.Id, session.RecoveryTx)
+	}
+	if session.Status == SessionStatusUnsigned {
+		return nil, fmt.Errorf("session %s has no signed commit transaction, nothing to recover", session.Id)
+	}
+	if session.Status == SessionStatusRevealSent && !force {
+		return nil, fmt.Errorf("session %s has sent its reveal transactions, recovering it double-spends them", session.Id)
+	}
+	feeRate, err := feeEstimator.EstimateFeeRate()
+	if err != nil {
+		return nil, err
+	}
+	changeAddr, err := walletCli.GetRawChangeAddressType(constants.DefaultWalletName, constants.AddressTypeP2shSegWit)
+	if err != nil {
+		return nil, err
+	}
+	changeScript, err := util.AddressScript(changeAddr.String(), util.ActiveNet.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	sweepTx, err := buildSweepTx(session, changeScript, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	sweepTxHash, err := walletCli.SendRawTransaction(sweepTx, false)
+	if err != nil {
+		return nil, err
+	}
+	log.Log.Info("recoveryTxSendSuccess", sweepTxHash)
+
+	session.Status = SessionStatusRecovered
+	session.RecoveryTx = sweepTx.TxHash().String()
+	if err := journal.Save(session); err != nil {
+		return nil, err
+	}
+	return sweepTx, nil
+}
+
+// buildSweepTx is a function that builds a transaction spending the commit outputs of
+// a session to the given script, with key path spends signed by the temporary key.
+func buildSweepTx(session *Session, pkScript []byte, feeRate int64) (*wire.MsgTx, error) {
+	commitTx, err := session.Commit()
+	if err != nil {
+		return nil, err
+	}
+	priKey, err := session.PriKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// spend every commit output with the key path of the temporary key
+	commitHash := commitTx.TxHash()
+	sweepTx := wire.NewMsgTx(2)
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	tapRoots := make([][]byte, 0, len(session.Reveals))
+	var inTotal int64
+	for _, reveal := range session.Reveals {
+		if int(reveal.Vout) >= len(commitTx.TxOut) {
+			return nil, fmt.Errorf("invalid session, commit transaction has no output %d", reveal.Vout)
+		}
+		revealScript, err := hex.DecodeString(reveal.Script)
+		if err != nil {
+			return nil, err
+		}
+		tapRoot := txscript.NewBaseTapLeaf(revealScript).TapHash()
+		outputKey := txscript.ComputeTaprootOutputKey(priKey.PubKey(), tapRoot[:])
+		outputScript, err := txscript.PayToTaprootScript(outputKey)
+		if err != nil {
+			return nil, err
+		}
+		prevOut := commitTx.TxOut[reveal.Vout]
+		if !bytes.Equal(outputScript, prevOut.PkScript) {
+			return nil, fmt.Errorf("invalid session, commit output %d is not paid to the reveal script", reveal.Vout)
+		}
+
+		outpoint := wire.NewOutPoint(&commitHash, reveal.Vout)
+		sweepTx.AddTxIn(wire.NewTxIn(outpoint, nil, wire.TxWitness{make([]byte, 64)}))
+		prevOuts[*outpoint] = prevOut
+		tapRoots = append(tapRoots, tapRoot[:])
+		inTotal += prevOut.Value
+	}
+
+	sweepTx.AddTxOut(wire.NewTxOut(0, pkScript))
+	value := inTotal - CalculateTxFee(sweepTx, feeRate)
+	if value < constants.DustLimit {
+		return nil, fmt.Errorf("commit outputs of %d sats cannot pay the sweep fee", inTotal)
+	}
+	sweepTx.TxOut[0].Value = value
+
+	prevFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(sweepTx, prevFetcher)
+	for idx, txIn := range sweepTx.TxIn {
+		prevOut := prevOuts[txIn.PreviousOutPoint]
+		sig, err := txscript.RawTxInTaprootSignature(sweepTx, sigHashes, idx, prevOut.Value, prevOut.PkScript,
+			tapRoots[idx], txscript.SigHashDefault, priKey)
+		if err != nil {
+			return nil, err
+		}
+		txIn.Witness = wire.TxWitness{sig}
+	}
+	return sweepTx, nil
+}
+
+// isTxKnownErr is a function that returns whether a send transaction error means
+// the transaction is already in the mempool or in the chain.
+func isTxKnownErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, known := range []string{"already have transaction", "txn-already-known", "txn-already-in-mempool", "already in block chain", "transaction already exists"} {
+		if strings.Contains(msg, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// serializeTx is a function that serializes a transaction with its witness to hex.
+func serializeTx(tx *wire.MsgTx) (string, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, tx.SerializeSize()))
+	if err := tx.Serialize(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// deserializeTx is a function that deserializes a transaction from hex.
+func deserializeTx(txHex string) (*wire.MsgTx, error) {
+	data, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, err
+	}
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	return tx, nil
+}
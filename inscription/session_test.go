@@ -0,0 +1,132 @@
+package inscription
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/inscription-c/cins/constants"
+	"github.com/inscription-c/cins/inscription/index/tables"
+	"github.com/inscription-c/cins/pkg/util"
+	"github.com/inscription-c/cins/pkg/util/txscript"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestSessionJournal(t *testing.T) {
+	journal, err := NewSessionJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := newTestSession(t)
+	assert.Equal(t, journal.Save(session), nil)
+
+	loaded, err := journal.Load(session.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loaded.Status, SessionStatusSigned)
+	assert.Equal(t, loaded.CommitTx, session.CommitTx)
+	assert.DeepEqual(t, loaded.Reveals, session.Reveals)
+
+	list, err := journal.List()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(list), 1)
+
+	_, err = journal.Load("unknown")
+	assert.Equal(t, err, ErrSessionNotFound)
+}
+
+func TestSessionNoBackup(t *testing.T) {
+	journal, err := NewSessionJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := newTestSession(t)
+	session.NoBackup = true
+
+	// the key is kept until the reveal transactions are signed
+	session.Status = SessionStatusUnsigned
+	assert.Equal(t, journal.Save(session), nil)
+	loaded, err := journal.Load(session.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loaded.PrivateKey, session.PrivateKey)
+
+	session.Status = SessionStatusSigned
+	assert.Equal(t, journal.Save(session), nil)
+	loaded, err = journal.Load(session.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, loaded.PrivateKey, "")
+	_, err = loaded.PriKey()
+	assert.ErrorContains(t, err, "not backed up")
+}
+
+func TestRecoverRevealSentSession(t *testing.T) {
+	session := newTestSession(t)
+	session.Status = SessionStatusRevealSent
+	_, err := recoverSession(nil, nil, session, nil, false)
+	assert.ErrorContains(t, err, "double-spends")
+}
+
+func TestBuildSweepTx(t *testing.T) {
+	session := newTestSession(t)
+	commitTx, err := session.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sweepTx, err := buildSweepTx(session, commitTx.TxOut[0].PkScript, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(sweepTx.TxIn), 1)
+	assert.Equal(t, sweepTx.TxIn[0].PreviousOutPoint.Hash.String(), session.Id)
+
+	prevOut := commitTx.TxOut[0]
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	engine, err := txscript.NewEngine(prevOut.PkScript, sweepTx, 0, txscript.StandardVerifyFlags,
+		nil, txscript.NewTxSigHashes(sweepTx, prevFetcher), prevOut.Value, prevFetcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, engine.Execute(), nil)
+}
+
+func newTestSession(t *testing.T) *Session {
+	priKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := &util.DefaultProtocol{}
+	body.Reset([]byte("cins"))
+	revealScript, err := InscriptionToScript(priKey.PubKey(), Header{
+		CInsDescription: &tables.CInsDescription{},
+		ContentType:     constants.ContentTypeJson,
+	}, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controlBlock, address, err := RevealScriptAddress(priKey.PubKey(), revealScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	controlBlockBytes, err := controlBlock.ToBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commitTx := wire.NewMsgTx(2)
+	commitTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	commitTx.AddTxOut(wire.NewTxOut(10000, pkScript))
+	commitHash := commitTx.TxHash()
+	revealTx := wire.NewMsgTx(2)
+	revealTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&commitHash, 0), nil,
+		wire.TxWitness{make([]byte, 64), revealScript, controlBlockBytes}))
+	revealTx.AddTxOut(wire.NewTxOut(9000, pkScript))
+	session, err := newSession(priKey, commitTx, []*wire.MsgTx{revealTx},
+		[]*SessionReveal{newSessionReveal(0, revealScript, controlBlockBytes)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
@@ -2,6 +2,7 @@ package indexer
 
 type IndexerInterface interface {
 	Outpoint(outpoint string) (*OutpointResp, error)
+	Outpoints(outpoints []string) (map[string][]string, error)
 }
 
 type OutpointResp struct {
@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -29,6 +30,26 @@ func (w *Indexer) Outpoint(outpoint string) (*OutpointResp, error) {
 	return outputResp, nil
 }
 
+// Outpoints returns the ids of the inscriptions held by every outpoint, with one request.
+func (w *Indexer) Outpoints(outpoints []string) (map[string][]string, error) {
+	body, err := json.Marshal(outpoints)
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/outputs", w.indexerUrl)
+	request, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	outputsResp := make(map[string][]string)
+
+	if err := w.doRetry(request, &outputsResp); err != nil {
+		return nil, err
+	}
+	return outputsResp, nil
+}
+
 func (w *Indexer) doRetry(request *http.Request, result interface{}) error {
 	idx := 0
 	for {
@@ -38,6 +59,14 @@ func (w *Indexer) doRetry(request *http.Request, result interface{}) error {
 				return fmt.Errorf("retry 3 times")
 			}
 			time.Sleep(time.Second)
+			// the body of the previous try has been read
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					return err
+				}
+				request.Body = body
+			}
 		}
 		resp, err := http.DefaultClient.Do(request)
 		if err != nil {
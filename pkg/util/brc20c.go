@@ -25,9 +25,8 @@ type CBRC20 struct {
 	Limit    string `json:"lim,omitempty"`
 	Decimals string `json:"dec,omitempty"`
 
-	//TkId   string `json:"tkid,omitempty"` // mint
-	//Amount string `json:"amt,omitempty"`
-	//To     string `json:"to,omitempty"`
+	TkId   string `json:"tkid,omitempty"` // mint, transfer
+	Amount string `json:"amt,omitempty"`
 }
 
 // Name is a method of the BRC20C struct.
@@ -51,7 +50,7 @@ func (b *CBRC20) Data() []byte {
 // It checks the BRC20C protocol.
 // It unmarshals the data into a new BRC20C struct and checks the protocol name, tick name, and operation.
 // If the operation is "deploy", it checks the max, limit, and decimals.
-// If the operation is "mint", it checks the tkId.
+// If the operation is "mint" or "transfer", it checks the tkId and the amount.
 // If the operation is not supported, it returns an error.
 func (b *CBRC20) Check() error {
 	p := &CBRC20{}
@@ -67,6 +66,8 @@ func (b *CBRC20) Check() error {
 
 	switch p.Operation {
 	case constants.OperationDeploy:
+		p.TkId = ""
+		p.Amount = ""
 		var err error
 		var tokenMax uint64
 		if p.Max != "" {
@@ -94,13 +95,20 @@ func (b *CBRC20) Check() error {
 				return err
 			}
 		}
-	//case constants.OperationMint:
-	//	p.Max = ""
-	//	p.Limit = ""
-	//	p.Decimals = ""
-	//	if tables.StringToInscriptionId(p.TkId) == nil {
-	//		return errors.New("tkid invalid")
-	//	}
+	case constants.OperationMint, constants.OperationTransfer:
+		p.Max = ""
+		p.Limit = ""
+		p.Decimals = ""
+		if !constants.InscriptionIdRegexp.MatchString(p.TkId) {
+			return errors.New("tkid invalid")
+		}
+		amount, err := strconv.ParseUint(p.Amount, 10, 64)
+		if err != nil {
+			return err
+		}
+		if amount == 0 {
+			return errors.New("amount must be greater than zero")
+		}
 	default:
 		return fmt.Errorf("op `%s` not support", p.Operation)
 	}
@@ -0,0 +1,40 @@
+package util
+
+import "testing"
+
+func TestCBRC20Check(t *testing.T) {
+	tkid := "b61b0172d95e266c18aea0c624db987e971a5d6d4ebc2aaed85da4642d635735i0"
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"deploy", `{"p":"c-brc-20","op":"deploy","tick":"cins","max":"21000000","lim":"1000"}`, true},
+		{"deploy limit over max", `{"p":"c-brc-20","op":"deploy","tick":"cins","max":"10","lim":"1000"}`, false},
+		{"mint", `{"p":"c-brc-20","op":"mint","tick":"cins","tkid":"` + tkid + `","amt":"1000"}`, true},
+		{"mint invalid tkid", `{"p":"c-brc-20","op":"mint","tick":"cins","tkid":"cins","amt":"1000"}`, false},
+		{"mint zero amount", `{"p":"c-brc-20","op":"mint","tick":"cins","tkid":"` + tkid + `","amt":"0"}`, false},
+		{"mint invalid amount", `{"p":"c-brc-20","op":"mint","tick":"cins","tkid":"` + tkid + `","amt":"-1"}`, false},
+		{"transfer", `{"p":"c-brc-20","op":"transfer","tick":"cins","tkid":"` + tkid + `","amt":"10"}`, true},
+		{"transfer without amount", `{"p":"c-brc-20","op":"transfer","tick":"cins","tkid":"` + tkid + `"}`, false},
+		{"unknown op", `{"p":"c-brc-20","op":"burn","tick":"cins"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &CBRC20{}
+			p.Reset([]byte(tt.body))
+			if err := p.Check(); (err == nil) != tt.ok {
+				t.Fatalf("check %s: got err %v, want ok %v", tt.body, err, tt.ok)
+			}
+		})
+	}
+
+	p := &CBRC20{}
+	p.Reset([]byte(`{"p":"c-brc-20","op":"mint","tick":"cins","tkid":"` + tkid + `","amt":"1000","max":"1"}`))
+	if err := p.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Max != "" || p.TkId != tkid || p.Amount != "1000" {
+		t.Fatalf("unexpected mint %+v", p)
+	}
+}
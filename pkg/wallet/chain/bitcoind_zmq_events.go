@@ -3,6 +3,7 @@ package chain
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/btcsuite/btcwallet/chain"
 	"io"
@@ -26,7 +27,8 @@ type ZMQConfig struct {
 	ZMQBlockHost string
 
 	// ZMQTxHost is the IP address and port of the bitcoind's rawtx
-	// listener.
+	// listener. If it is empty, only blocks are subscribed to and no
+	// mempool is kept.
 	ZMQTxHost string
 
 	// ZMQReadDeadline represents the read deadline we'll apply when reading
@@ -68,7 +70,7 @@ type bitcoindZMQEvents struct {
 	blockConn *gozmq.Conn
 
 	// txConn is the ZMQ connection we'll use to read raw transaction
-	// events.
+	// events. It is nil if only blocks are subscribed to.
 	txConn *gozmq.Conn
 
 	// blockNtfns is a channel to which any new blocks will be sent.
@@ -129,18 +131,24 @@ func newBitcoindZMQEvents(cfg *ZMQConfig, client *rpcclient.Client,
 			"events: %v", err)
 	}
 
-	zmqTxConn, err := gozmq.Subscribe(
-		cfg.ZMQTxHost, []string{rawTxZMQCommand}, cfg.ZMQReadDeadline,
-	)
-	if err != nil {
-		// Ensure that the block zmq connection is closed in the case
-		// that it succeeded but the tx zmq connection failed.
-		if err := zmqBlockConn.Close(); err != nil {
-			log.Log.Errorf("could not close zmq block conn: %v", err)
-		}
+	var zmqTxConn *gozmq.Conn
+	if cfg.ZMQTxHost != "" {
+		zmqTxConn, err = gozmq.Subscribe(
+			cfg.ZMQTxHost, []string{rawTxZMQCommand},
+			cfg.ZMQReadDeadline,
+		)
+		if err != nil {
+			// Ensure that the block zmq connection is closed in the
+			// case that it succeeded but the tx zmq connection
+			// failed.
+			if err := zmqBlockConn.Close(); err != nil {
+				log.Log.Errorf("could not close zmq block "+
+					"conn: %v", err)
+			}
 
-		return nil, fmt.Errorf("unable to subscribe for zmq tx "+
-			"events: %v", err)
+			return nil, fmt.Errorf("unable to subscribe for zmq "+
+				"tx events: %v", err)
+		}
 	}
 
 	// Create the config for mempool and attach default values if not
@@ -177,6 +185,13 @@ func newBitcoindZMQEvents(cfg *ZMQConfig, client *rpcclient.Client,
 
 // Start spins off the bitcoindZMQEvent goroutines.
 func (b *bitcoindZMQEvents) Start() error {
+	// Without a transaction subscription only blocks are delivered.
+	if b.txConn == nil {
+		b.wg.Add(1)
+		go b.blockEventHandler()
+		return nil
+	}
+
 	// Load the mempool so we don't miss transactions, but only if we need
 	// one.
 	if !b.hasPrevoutRPC {
@@ -198,8 +213,10 @@ func (b *bitcoindZMQEvents) Stop() error {
 	b.mempool.Shutdown()
 
 	var returnErr error
-	if err := b.txConn.Close(); err != nil {
-		returnErr = err
+	if b.txConn != nil {
+		if err := b.txConn.Close(); err != nil {
+			returnErr = err
+		}
 	}
 
 	if err := b.blockConn.Close(); err != nil {
@@ -296,8 +313,10 @@ func (b *bitcoindZMQEvents) blockEventHandler() {
 		bufs, err = b.blockConn.Receive(bufs)
 		if err != nil {
 			// EOF should only be returned if the connection was
-			// explicitly closed, so we can exit at this point.
-			if err == io.EOF {
+			// explicitly closed, so we can exit at this point. A
+			// connection closed while reading returns a closed
+			// network connection error instead.
+			if err == io.EOF || errors.Is(err, net.ErrClosed) {
 				return
 			}
 
@@ -393,8 +412,10 @@ func (b *bitcoindZMQEvents) txEventHandler() {
 		bufs, err = b.txConn.Receive(bufs)
 		if err != nil {
 			// EOF should only be returned if the connection was
-			// explicitly closed, so we can exit at this point.
-			if err == io.EOF {
+			// explicitly closed, so we can exit at this point. A
+			// connection closed while reading returns a closed
+			// network connection error instead.
+			if err == io.EOF || errors.Is(err, net.ErrClosed) {
 				return
 			}
 
@@ -9,12 +9,14 @@ import (
 	"fmt"
 	chain2 "github.com/inscription-c/cins/pkg/wallet/chain"
 	"github.com/inscription-c/cins/pkg/wallet/wallet"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
@@ -105,6 +107,7 @@ var rpcHandlers = map[string]struct {
 	"walletlock":             {handler: walletLock},
 	"walletpassphrase":       {handler: walletPassphrase},
 	"walletpassphrasechange": {handler: walletPassphraseChange},
+	"walletprocesspsbt":      {handler: walletProcessPsbt},
 
 	// Reference implementation methods (still unimplemented)
 	"backupwallet":         {handler: unimplemented, noHelp: true},
@@ -1886,6 +1889,44 @@ func verifyMessage(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
 	}
 }
 
+// walletProcessPsbt handles the walletprocesspsbt command. It signs the inputs
+// of the PSBT spending outputs of the wallet and finalizes the PSBT if every
+// input is signed. Only the SIGHASH_ALL signature hash type is supported.
+func walletProcessPsbt(icmd interface{}, w *wallet.Wallet) (interface{}, error) {
+	cmd := icmd.(*btcjson.WalletProcessPsbtCmd)
+
+	if cmd.SighashType != nil && *cmd.SighashType != "ALL" && *cmd.SighashType != "DEFAULT" {
+		return nil, InvalidParameterError{fmt.Errorf("unsupported sighash type %s", *cmd.SighashType)}
+	}
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(cmd.Psbt), true)
+	if err != nil {
+		return nil, DeserializationError{err}
+	}
+
+	if cmd.Sign != nil && !*cmd.Sign {
+		// only add the UTXO information of the wallet inputs
+		if err := w.DecorateInputs(packet, false); err != nil {
+			return nil, err
+		}
+	} else {
+		if w.Locked() {
+			return nil, &ErrWalletUnlockNeeded
+		}
+		if err := w.FinalizePsbt(nil, waddrmgr.DefaultAccountNum, packet); err != nil {
+			return nil, err
+		}
+	}
+
+	b64, err := packet.B64Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &btcjson.WalletProcessPsbtResult{
+		Psbt:     b64,
+		Complete: packet.IsComplete(),
+	}, nil
+}
+
 // walletIsLocked handles the walletislocked extension request by
 // returning the current lock state (false for unlocked, true for locked)
 // of an account.